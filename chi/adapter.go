@@ -0,0 +1,97 @@
+// Package chiadapter adds chi support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to the chi.Mux
+package chiadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/go-chi/chi"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+func init() {
+	core.RegisterAdapter("chi", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		c, ok := app.(*chi.Mux)
+		if !ok {
+			return nil, fmt.Errorf("chiadapter: %T is not a *chi.Mux", app)
+
+		}
+		return New(c, opts...), nil
+
+	})
+
+}
+
+// ChiLambda makes it easy to send ALB events to a chi.Mux.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter
+type ChiLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the ChiLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Chi *chi.Mux
+}
+
+// New creates a new instance of the ChiLambda object.
+// Receives an initialized *chi.Mux object - normally created with chi.NewRouter().
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the ChiLambda object.
+func New(c *chi.Mux, opts ...core.Option) *ChiLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](c, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](c *chi.Mux, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Chi: c}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the chi.Mux for routing.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (c *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return c.Handle(ctx, req, c.Chi)
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (c *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return c.Stream(ctx, req, c.Chi)
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (c *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return c.HandleRaw(ctx, payload, c.Chi)
+}
+
+// GetALBContext retrieve the ALBTargetGroupRequestContext of a request routed
+// by chi, for use in handlers and middlewares.
+func GetALBContext(r *http.Request) (events.ALBTargetGroupRequestContext, bool) {
+	return core.GetALBContextFromContext(r.Context())
+}
+
+// GetRuntimeContext retrieve the Lambda Runtime Context of a request routed
+// by chi, for use in handlers and middlewares.
+func GetRuntimeContext(r *http.Request) (*lambdacontext.LambdaContext, bool) {
+	return core.GetRuntimeContextFromContext(r.Context())
+}
@@ -0,0 +1,93 @@
+// Package connectadapter adds connect-go support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance, the Mount method to register Connect service handlers
+// and Proxy method to send request to them.
+// ALB talks HTTP/1.1 to Lambda, so services are reachable with the Connect
+// and gRPC-Web protocols, not with plain gRPC.
+package connectadapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+// BinaryContentTypes are the protobuf media types of the Connect and
+// gRPC-Web protocols. Their bodies are always base64 encoded in ALB responses.
+var BinaryContentTypes = []string{
+	"application/proto",
+	"application/connect+proto",
+	"application/grpc-web",
+	"application/grpc-web+proto",
+}
+
+// ConnectLambda makes it easy to send ALB events to connect-go handlers.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter.
+// Request bodies are base64 decoded by the core package and content-type
+// negotiation between JSON and protobuf is left to the Connect handlers.
+type ConnectLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the ConnectLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Mux *http.ServeMux
+}
+
+// New creates a new instance of the ConnectLambda object with an empty
+// http.ServeMux. Responses with one of the BinaryContentTypes are always base64
+// encoded. Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the ConnectLambda object.
+func New(opts ...core.Option) *ConnectLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Mux: http.NewServeMux()}
+	l.Configure(core.WithBinaryContentTypes(BinaryContentTypes...))
+	l.Configure(opts...)
+	return l
+
+}
+
+// Mount registers a Connect service handler, as returned by the generated
+// New<Service>Handler functions:
+//
+//	l.Mount(greetv1connect.NewGreetServiceHandler(&greetServer{}))
+func (c *Lambda[E, R]) Mount(path string, handler http.Handler) {
+	c.Mux.Handle(path, handler)
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the mounted Connect handlers.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (c *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return c.Handle(ctx, req, c.Mux)
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (c *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return c.Stream(ctx, req, c.Mux)
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (c *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return c.HandleRaw(ctx, payload, c.Mux)
+}
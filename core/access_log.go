@@ -0,0 +1,65 @@
+package core
+
+import (
+	"net/http"
+	"time"
+)
+
+// AccessLogEntry describes a request served through RequestAccessor.Handle.
+type AccessLogEntry struct {
+	Method string
+	Path   string
+	// Query is the raw query string with the values of the parameters set
+	// with WithRedactedQueryParams replaced by ***.
+	Query    string
+	Status   int
+	Duration time.Duration
+
+	// RequestHeaderCount is the number of header values of the request.
+	RequestHeaderCount int
+	// RequestBodySize is the size in bytes of the decoded request body.
+	RequestBodySize int64
+	// ResponseBodySize is the size in bytes of the response body before any
+	// base64 encoding.
+	ResponseBodySize int
+}
+
+// responseStats is what the access log and the metrics read from the
+// response writer given to the handler.
+type responseStats interface {
+	Status() int
+	Size() int
+}
+
+// newAccessLogEntry describes req, served in elapsed with the response
+// written into w, with its query string not yet redacted.
+func newAccessLogEntry(req *http.Request, w responseStats, elapsed time.Duration) AccessLogEntry {
+	headerCount := 0
+	for _, values := range req.Header {
+		headerCount += len(values)
+
+	}
+	return AccessLogEntry{
+		Method:             req.Method,
+		Path:               req.URL.Path,
+		Query:              req.URL.RawQuery,
+		Status:             w.Status(),
+		Duration:           elapsed,
+		RequestHeaderCount: headerCount,
+		RequestBodySize:    req.ContentLength,
+		ResponseBodySize:   w.Size(),
+	}
+
+}
+
+// logAccess sends entry to the configured access log, if any.
+func (r *settings) logAccess(entry AccessLogEntry) {
+	if r.accessLog == nil {
+		return
+
+	}
+
+	entry.Query = r.redactQuery(entry.Query)
+	r.accessLog(entry)
+
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestAccessLogSizes(t *testing.T) {
+	var entry AccessLogEntry
+	r := NewRequestAccessor(WithAccessLog(func(e AccessLogEntry) { entry = e }))
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "created")
+
+	})
+	req := events.ALBTargetGroupRequest{
+		HTTPMethod:        http.MethodPost,
+		Path:              "/items",
+		MultiValueHeaders: map[string][]string{"x-a": {"1", "2"}, "x-b": {"3"}},
+		Body:              "hello",
+	}
+	if _, err := r.Handle(context.Background(), req, h); err != nil {
+		t.Fatal(err)
+
+	}
+
+	want := AccessLogEntry{
+		Method:             http.MethodPost,
+		Path:               "/items",
+		Status:             http.StatusCreated,
+		Duration:           entry.Duration,
+		RequestHeaderCount: 3,
+		RequestBodySize:    5,
+		ResponseBodySize:   7,
+	}
+	if entry != want {
+		t.Errorf("got %+v, want %+v", entry, want)
+
+	}
+
+}
+
+func TestAccessLogRedactsQueryParams(t *testing.T) {
+	var entry AccessLogEntry
+	r := NewRequestAccessor(
+		WithAccessLog(func(e AccessLogEntry) { entry = e }),
+		WithRedactedQueryParams([]string{"token"}),
+	)
+	var token string
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token = req.URL.Query().Get("token")
+		w.WriteHeader(http.StatusOK)
+
+	})
+	req := events.ALBTargetGroupRequest{
+		HTTPMethod:            http.MethodGet,
+		Path:                  "/",
+		QueryStringParameters: map[string]string{"token": "abc"},
+	}
+	if _, err := r.Handle(context.Background(), req, h); err != nil {
+		t.Fatal(err)
+
+	}
+	if entry.Query != "token=***" {
+		t.Errorf("logged query %q, want token=***", entry.Query)
+
+	}
+	if token != "abc" {
+		t.Errorf("handler got token %q, want abc", token)
+
+	}
+
+}
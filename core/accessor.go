@@ -0,0 +1,163 @@
+package core
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Event is the set of Lambda events an Accessor converts into http.Request
+// objects.
+type Event interface {
+	events.ALBTargetGroupRequest | events.APIGatewayProxyRequest | events.APIGatewayV2HTTPRequest |
+		events.LambdaFunctionURLRequest | CloudFrontEvent | VPCLatticeRequest | VPCLatticeRequestV2
+}
+
+// Response is the set of Lambda responses an Accessor builds from what the
+// http.Handler wrote.
+type Response interface {
+	events.ALBTargetGroupResponse | events.APIGatewayProxyResponse | events.APIGatewayV2HTTPResponse |
+		events.LambdaFunctionURLResponse | CloudFrontResponse | VPCLatticeResponse
+}
+
+// Accessor converts events of type E into http.Request objects and what the
+// handler wrote into responses of type R. Framework adapters embed it to
+// serve any trigger with the same application code: an adapter created with
+// NewLambda[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse]
+// sits behind an API Gateway REST API instead of an ALB.
+type Accessor[E Event, R Response] struct {
+	settings
+}
+
+// RequestAccessor is the Accessor of ALB events.
+type RequestAccessor = Accessor[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// NewRequestAccessor returns a RequestAccessor configured with opts, such as
+// WithServerAddress, WithStripBasePath or WithTrustedProxies.
+func NewRequestAccessor(opts ...Option) *RequestAccessor {
+	r := &RequestAccessor{}
+	r.Configure(opts...)
+	return r
+
+}
+
+// NewAccessor returns an Accessor of events of type E configured with opts.
+func NewAccessor[E Event, R Response](opts ...Option) *Accessor[E, R] {
+	r := &Accessor[E, R]{}
+	r.Configure(opts...)
+	return r
+
+}
+
+// EventToRequestWithContext converts an event and context into an http.Request object.
+// Returns the populated http request with lambda context and event request context as part of its context.
+// Access those using GetALBContextFromContext, GetAPIGatewayContextFromContext, GetHTTPAPIContextFromContext,
+// GetFunctionURLContextFromContext, GetCloudFrontConfigFromContext, GetVPCLatticeContextFromContext, GetPathParametersFromContext
+// and GetRuntimeContextFromContext functions in this package. ALB events carry no stage, so GetStageFromContext reports false for them.
+func (r *Accessor[E, R]) EventToRequestWithContext(ctx context.Context, req E) (*http.Request, error) {
+	ev := newEvent(req)
+	httpRequest, err := r.eventToRequestWithContext(ctx, ev)
+	if err != nil {
+		return nil, err
+
+	}
+	restoreHeaderCase(httpRequest.Header, r.headerNames(ev))
+	return httpRequest, nil
+
+}
+
+// EventToRequest converts an event into an http.Request object.
+// Returns the populated request maintaining headers
+func (r *Accessor[E, R]) EventToRequest(req E) (*http.Request, error) {
+	return r.eventToRequest(newEvent(req))
+
+}
+
+// EventRequestURI returns the path and query string of an event as
+// EventToRequest would route them, with the base path stripped. It lets
+// adapters that do not go through an http.Request route exactly like the
+// others, though only Handle applies every option.
+func (r *Accessor[E, R]) EventRequestURI(req E) (string, error) {
+	return r.eventRequestURI(newEvent(req))
+
+}
+
+// EventContext returns a copy of ctx carrying the same values as the context
+// of the requests built by EventToRequestWithContext, for adapters that do not
+// go through an http.Request.
+func (r *Accessor[E, R]) EventContext(ctx context.Context, req E) context.Context {
+	return r.eventContext(ctx, newEvent(req))
+
+}
+
+// Handle converts an event and context into an http.Request, serves it
+// with h and converts what h wrote into a response of type R.
+// Framework adapters call Handle from their ProxyWithContext method so that
+// every option of the Accessor applies regardless of the framework.
+func (r *Accessor[E, R]) Handle(ctx context.Context, req E, h http.Handler) (R, error) {
+	resp, err := r.handle(ctx, newEvent(req), h)
+	return FromALBResponse[R](resp), err
+
+}
+
+// WriteResponse converts what a handler wrote into w into a response of
+// type R.
+func (r *Accessor[E, R]) WriteResponse(w *ProxyResponseWriter) (R, error) {
+	resp, err := w.GetProxyResponse()
+	return FromALBResponse[R](resp), err
+
+}
+
+// DryRun converts req, serves it synchronously with handler and converts the
+// result, exactly like Handle minus the Lambda context, the maintenance mode
+// and the concurrency limit. It returns the http.Request given to the handler
+// along with the response so event fixtures can be validated in CI.
+func (r *Accessor[E, R]) DryRun(req E, handler http.Handler) (*http.Request, R, error) {
+	httpRequest, resp, err := r.serve(context.Background(), newEvent(req), handler, func() {})
+	return httpRequest, FromALBResponse[R](resp), err
+
+}
+
+// DecodeEventBody returns the body of an event, base64 decoded when the
+// event says so.
+func DecodeEventBody[E Event](req E) ([]byte, error) {
+	return decodeEventBody(newEvent(req))
+
+}
+
+// FromALBResponse converts resp, as built by a ProxyResponseWriter, into a
+// response of type R. Adapters producing their response without Handle use
+// it to answer any trigger.
+func FromALBResponse[R Response](resp events.ALBTargetGroupResponse) R {
+	var out R
+	switch p := any(&out).(type) {
+	case *events.ALBTargetGroupResponse:
+		*p = resp
+
+	case *events.APIGatewayProxyResponse:
+		*p = ALBToAPIGatewayResponse(resp)
+
+	case *events.APIGatewayV2HTTPResponse:
+		*p = ALBToHTTPAPIResponse(resp)
+
+	case *events.LambdaFunctionURLResponse:
+		*p = ALBToFunctionURLResponse(resp)
+
+	case *CloudFrontResponse:
+		*p = ALBToCloudFrontResponse(resp)
+
+	case *VPCLatticeResponse:
+		*p = ALBToVPCLatticeResponse(resp)
+
+	}
+	return out
+
+}
+
+// ToALBRequest converts req into the ALB event the conversion works on,
+// for adapters reading the event fields without Handle.
+func ToALBRequest[E Event](req E) events.ALBTargetGroupRequest {
+	return newEvent(req).ALBTargetGroupRequest
+
+}
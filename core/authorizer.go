@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Authorizer is what the API Gateway authorizer of a request returned, in the
+// same form for REST APIs and HTTP APIs.
+type Authorizer struct {
+	// PrincipalID identifies the caller: the principalId of a Lambda
+	// authorizer, the sub claim of a JWT or the user ARN of an IAM caller.
+	PrincipalID string
+	// Context holds the key/values returned by a Lambda authorizer.
+	Context map[string]interface{}
+	// Claims holds the claims of the JWT validated by a JWT or Cognito
+	// user pool authorizer.
+	Claims map[string]string
+	// Scopes holds the OAuth scopes of the JWT, for HTTP APIs.
+	Scopes []string
+}
+
+// GetAuthorizerFromContext retrieve the API Gateway authorizer output from context.Context.
+// Returns false for requests that were not authorized by an API Gateway authorizer.
+func GetAuthorizerFromContext(ctx context.Context) (Authorizer, bool) {
+	restAPI, isRESTAPI := FromContext[events.APIGatewayProxyRequestContext](ctx)
+	httpAPI, isHTTPAPI := FromContext[events.APIGatewayV2HTTPRequestContext](ctx)
+	switch {
+	case isRESTAPI && len(restAPI.Authorizer) > 0:
+		return restAPIAuthorizer(restAPI.Authorizer), true
+
+	case isHTTPAPI && httpAPI.Authorizer != nil:
+		var authorizer Authorizer
+		description := httpAPI.Authorizer
+		authorizer.Context = description.Lambda
+		if description.JWT != nil {
+			authorizer.Claims = description.JWT.Claims
+			authorizer.Scopes = description.JWT.Scopes
+			authorizer.PrincipalID = description.JWT.Claims["sub"]
+
+		}
+		if description.IAM != nil {
+			authorizer.PrincipalID = description.IAM.UserARN
+
+		}
+		return authorizer, true
+
+	}
+	return Authorizer{}, false
+
+}
+
+// restAPIAuthorizer converts the authorizer block of a REST API request, in
+// which API Gateway mixes the principalId, the Cognito claims, its own
+// integrationLatency and the context returned by a Lambda authorizer.
+func restAPIAuthorizer(values map[string]interface{}) Authorizer {
+	authorizer := Authorizer{Context: make(map[string]interface{})}
+	for key, value := range values {
+		switch key {
+		case "principalId":
+			authorizer.PrincipalID = fmt.Sprint(value)
+
+		case "claims":
+			claims, _ := value.(map[string]interface{})
+			authorizer.Claims = make(map[string]string, len(claims))
+			for name, claim := range claims {
+				authorizer.Claims[name] = fmt.Sprint(claim)
+
+			}
+
+		case "integrationLatency":
+
+		default:
+			authorizer.Context[key] = value
+
+		}
+
+	}
+	if authorizer.PrincipalID == "" {
+		authorizer.PrincipalID = authorizer.Claims["sub"]
+
+	}
+	return authorizer
+
+}
+
+// CognitoClaims are the claims of a Cognito user pool token validated by an
+// API Gateway authorizer.
+type CognitoClaims struct {
+	Subject  string
+	Email    string
+	Username string
+	Groups   []string
+	// Claims holds all the claims of the token.
+	Claims map[string]string
+}
+
+// GetCognitoClaimsFromContext retrieve the claims of the Cognito token that
+// authorized the request from context.Context.
+// Returns false for requests that were not authorized with a JWT.
+func GetCognitoClaimsFromContext(ctx context.Context) (CognitoClaims, bool) {
+	authorizer, ok := GetAuthorizerFromContext(ctx)
+	if !ok || len(authorizer.Claims) == 0 {
+		return CognitoClaims{}, false
+
+	}
+
+	claims := authorizer.Claims
+	username := claims["cognito:username"]
+	if username == "" {
+		// Access tokens name it username.
+		username = claims["username"]
+
+	}
+	return CognitoClaims{
+		Subject:  claims["sub"],
+		Email:    claims["email"],
+		Username: username,
+		Groups:   parseGroups(claims["cognito:groups"]),
+		Claims:   claims,
+	}, true
+
+}
+
+// parseGroups splits the cognito:groups claim, which API Gateway flattens to
+// a string such as [admin editors], admin,editors or ["admin","editors"].
+func parseGroups(claim string) []string {
+	claim = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(claim), "["), "]")
+	var groups []string
+	for _, group := range strings.FieldsFunc(claim, func(c rune) bool { return c == ',' || c == ' ' }) {
+		if group = strings.Trim(group, `"`); group != "" {
+			groups = append(groups, group)
+
+		}
+
+	}
+	return groups
+
+}
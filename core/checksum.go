@@ -0,0 +1,38 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+)
+
+// verifyChecksum compares the checksum header of req, base64 or hex encoded,
+// with the checksum of its body and returns a 400 *HTTPError when they
+// differ. Requests without the header are not checked. As Content-MD5, the
+// checksum covers the body as sent, before any decompression.
+func (r *settings) verifyChecksum(req *http.Request, body io.Reader) error {
+	expected := req.Header.Get(r.checksumHeader)
+	if expected == "" {
+		return nil
+
+	}
+
+	h := r.checksumHash()
+	if _, err := io.Copy(h, body); err != nil {
+		return badRequest("invalid request body: " + err.Error())
+
+	}
+	sum := h.Sum(nil)
+	if decoded, err := base64.StdEncoding.DecodeString(expected); err == nil && bytes.Equal(decoded, sum) {
+		return nil
+
+	}
+	if decoded, err := hex.DecodeString(expected); err == nil && bytes.Equal(decoded, sum) {
+		return nil
+
+	}
+	return badRequest(r.checksumHeader + " does not match the request body")
+
+}
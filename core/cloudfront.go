@@ -0,0 +1,161 @@
+package core
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// CloudFrontEvent is the event Lambda@Edge sends to viewer request and origin
+// request functions.
+type CloudFrontEvent struct {
+	Records []CloudFrontRecord `json:"Records"`
+}
+
+// CloudFrontRecord is a record of a CloudFrontEvent.
+type CloudFrontRecord struct {
+	CF CloudFrontRecordData `json:"cf"`
+}
+
+// CloudFrontRecordData holds the distribution configuration and the request
+// of a CloudFrontRecord.
+type CloudFrontRecordData struct {
+	Config  CloudFrontConfig  `json:"config"`
+	Request CloudFrontRequest `json:"request"`
+}
+
+// CloudFrontConfig describes the distribution and the trigger of a
+// Lambda@Edge invocation.
+type CloudFrontConfig struct {
+	DistributionDomainName string `json:"distributionDomainName"`
+	DistributionID         string `json:"distributionId"`
+	EventType              string `json:"eventType"`
+	RequestID              string `json:"requestId"`
+}
+
+// CloudFrontRequest is the request CloudFront received from the viewer.
+type CloudFrontRequest struct {
+	ClientIP    string            `json:"clientIp"`
+	Headers     CloudFrontHeaders `json:"headers"`
+	Method      string            `json:"method"`
+	QueryString string            `json:"querystring"`
+	URI         string            `json:"uri"`
+	Body        *CloudFrontBody   `json:"body,omitempty"`
+}
+
+// CloudFrontBody is the body of a CloudFrontRequest, included when the
+// function is configured to receive it. Requests whose body CloudFront
+// truncated are rejected with a 413.
+type CloudFrontBody struct {
+	InputTruncated bool   `json:"inputTruncated"`
+	Action         string `json:"action"`
+	Encoding       string `json:"encoding"`
+	Data           string `json:"data"`
+}
+
+// CloudFrontHeaders maps lower-cased header names to their values.
+type CloudFrontHeaders map[string][]CloudFrontHeader
+
+// CloudFrontHeader is a header value along with the original case of its name.
+type CloudFrontHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// CloudFrontResponse is the response a viewer request or origin request
+// function generates instead of forwarding the request to the origin.
+type CloudFrontResponse struct {
+	Status            string            `json:"status"`
+	StatusDescription string            `json:"statusDescription,omitempty"`
+	Headers           CloudFrontHeaders `json:"headers,omitempty"`
+	BodyEncoding      string            `json:"bodyEncoding,omitempty"`
+	Body              string            `json:"body,omitempty"`
+}
+
+// cloudFrontEvent converts the first record of a Lambda@Edge event, the only
+// one CloudFront ever sends. The client IP is added to X-Forwarded-For as
+// CloudFront would when forwarding the request.
+func cloudFrontEvent(req CloudFrontEvent) event {
+	if len(req.Records) == 0 {
+		return event{}
+
+	}
+	record := req.Records[0].CF
+
+	headers := make(map[string]string, len(record.Request.Headers)+1)
+	multiValueHeaders := make(map[string][]string, len(record.Request.Headers)+1)
+	for name, values := range record.Request.Headers {
+		for _, v := range values {
+			multiValueHeaders[name] = append(multiValueHeaders[name], v.Value)
+
+		}
+		headers[name] = strings.Join(multiValueHeaders[name], ",")
+
+	}
+	if record.Request.ClientIP != "" {
+		forwardedFor := record.Request.ClientIP
+		if previous := headers["x-forwarded-for"]; previous != "" {
+			forwardedFor = previous + ", " + forwardedFor
+
+		}
+		headers["x-forwarded-for"] = forwardedFor
+		multiValueHeaders["x-forwarded-for"] = []string{forwardedFor}
+
+	}
+
+	ev := event{
+		ALBTargetGroupRequest: events.ALBTargetGroupRequest{
+			HTTPMethod:        record.Request.Method,
+			Path:              record.Request.URI,
+			Headers:           headers,
+			MultiValueHeaders: multiValueHeaders,
+		},
+		rawQuery:         record.Request.QueryString,
+		cloudFrontConfig: &record.Config,
+	}
+	if body := record.Request.Body; body != nil {
+		ev.Body = body.Data
+		ev.IsBase64Encoded = body.Encoding == "base64"
+		ev.bodyTruncated = body.InputTruncated
+
+	}
+	return ev
+
+}
+
+// ALBToCloudFrontResponse converts an ALB response into a Lambda@Edge
+// generated response.
+func ALBToCloudFrontResponse(resp events.ALBTargetGroupResponse) CloudFrontResponse {
+	out := CloudFrontResponse{
+		Status:            strconv.Itoa(resp.StatusCode),
+		StatusDescription: http.StatusText(resp.StatusCode),
+		Headers:           make(CloudFrontHeaders, len(resp.Headers)+len(resp.MultiValueHeaders)),
+		BodyEncoding:      "text",
+		Body:              resp.Body,
+	}
+	if resp.IsBase64Encoded {
+		out.BodyEncoding = "base64"
+
+	}
+	for name, value := range singleValueOnly(resp) {
+		out.Headers.add(name, value)
+
+	}
+	for name, values := range resp.MultiValueHeaders {
+		for _, v := range values {
+			out.Headers.add(name, v)
+
+		}
+
+	}
+	return out
+
+}
+
+func (h CloudFrontHeaders) add(name string, value string) {
+	key := strings.ToLower(name)
+	h[key] = append(h[key], CloudFrontHeader{Key: http.CanonicalHeaderKey(name), Value: value})
+
+}
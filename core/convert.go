@@ -0,0 +1,159 @@
+package core
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// ALBToAPIGatewayResponse converts an ALB response into an API Gateway proxy
+// response. The status description, which API Gateway does not support, is
+// dropped.
+func ALBToAPIGatewayResponse(resp events.ALBTargetGroupResponse) events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		StatusCode:        resp.StatusCode,
+		Headers:           copyHeaders(resp.Headers),
+		MultiValueHeaders: copyMultiValueHeaders(resp.MultiValueHeaders),
+		Body:              resp.Body,
+		IsBase64Encoded:   resp.IsBase64Encoded,
+	}
+
+}
+
+// APIGatewayToALBResponse converts an API Gateway proxy response into an ALB
+// response, deriving the status description ALB requires from the status code.
+func APIGatewayToALBResponse(resp events.APIGatewayProxyResponse) events.ALBTargetGroupResponse {
+	return events.ALBTargetGroupResponse{
+		StatusCode:        resp.StatusCode,
+		StatusDescription: description(resp.StatusCode),
+		Headers:           copyHeaders(resp.Headers),
+		MultiValueHeaders: copyMultiValueHeaders(resp.MultiValueHeaders),
+		Body:              resp.Body,
+		IsBase64Encoded:   resp.IsBase64Encoded,
+	}
+
+}
+
+// ALBToHTTPAPIResponse converts an ALB response into an API Gateway HTTP API
+// response. HTTP APIs have no multi-value headers: values are joined with a
+// comma, except for Set-Cookie headers which become the cookies of the
+// response.
+func ALBToHTTPAPIResponse(resp events.ALBTargetGroupResponse) events.APIGatewayV2HTTPResponse {
+	out := events.APIGatewayV2HTTPResponse{
+		StatusCode:      resp.StatusCode,
+		Body:            resp.Body,
+		IsBase64Encoded: resp.IsBase64Encoded,
+	}
+	headers := make(map[string]string, len(resp.Headers)+len(resp.MultiValueHeaders))
+	for name, value := range singleValueOnly(resp) {
+		if http.CanonicalHeaderKey(name) == "Set-Cookie" {
+			out.Cookies = append(out.Cookies, splitSetCookie(value)...)
+			continue
+
+		}
+		headers[name] = value
+
+	}
+	for name, values := range resp.MultiValueHeaders {
+		if http.CanonicalHeaderKey(name) == "Set-Cookie" {
+			out.Cookies = append(out.Cookies, values...)
+			continue
+
+		}
+		headers[name] = strings.Join(values, ",")
+
+	}
+	out.Headers = headers
+	return out
+
+}
+
+// ALBToFunctionURLResponse converts an ALB response into a Lambda Function
+// URL response, with the same header handling as ALBToHTTPAPIResponse.
+func ALBToFunctionURLResponse(resp events.ALBTargetGroupResponse) events.LambdaFunctionURLResponse {
+	httpAPI := ALBToHTTPAPIResponse(resp)
+	return events.LambdaFunctionURLResponse{
+		StatusCode:      httpAPI.StatusCode,
+		Headers:         httpAPI.Headers,
+		Body:            httpAPI.Body,
+		IsBase64Encoded: httpAPI.IsBase64Encoded,
+		Cookies:         httpAPI.Cookies,
+	}
+
+}
+
+// splitSetCookie splits Set-Cookie header values joined with a comma, as
+// WithSingleValueHeaders does, without splitting the commas of Expires dates:
+// a comma only separates two cookies when followed by a name=value pair.
+func splitSetCookie(value string) []string {
+	var cookies []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		if value[i] != ',' || !startsWithCookiePair(value[i+1:]) {
+			continue
+
+		}
+		cookies = append(cookies, strings.TrimSpace(value[start:i]))
+		start = i + 1
+
+	}
+	return append(cookies, strings.TrimSpace(value[start:]))
+
+}
+
+// startsWithCookiePair reports whether s, once trimmed, starts with a
+// cookie name followed by an equal sign.
+func startsWithCookiePair(s string) bool {
+	s = strings.TrimLeft(s, " ")
+	end := strings.IndexAny(s, "=;, ")
+	return end > 0 && s[end] == '='
+
+}
+
+func copyHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+
+	}
+	copied := make(map[string]string, len(headers))
+	for k, v := range headers {
+		copied[k] = v
+
+	}
+	return copied
+
+}
+
+func copyMultiValueHeaders(headers map[string][]string) map[string][]string {
+	if headers == nil {
+		return nil
+
+	}
+	copied := make(map[string][]string, len(headers))
+	for k, v := range headers {
+		copied[k] = append([]string(nil), v...)
+
+	}
+	return copied
+
+}
+
+// singleValueOnly returns the single-value headers of resp that are not also
+// multi-value headers, as with WithDualHeaders, where the multi-value ones win.
+func singleValueOnly(resp events.ALBTargetGroupResponse) map[string]string {
+	if len(resp.MultiValueHeaders) == 0 {
+		return resp.Headers
+
+	}
+	headers := make(map[string]string, len(resp.Headers))
+	for name, value := range resp.Headers {
+		if _, ok := resp.MultiValueHeaders[name]; !ok {
+			headers[name] = value
+
+		}
+
+	}
+	return headers
+
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestALBAndAPIGatewayResponsesRoundTrip(t *testing.T) {
+	alb := events.ALBTargetGroupResponse{
+		StatusCode:        http.StatusOK,
+		StatusDescription: description(http.StatusOK),
+		Headers:           map[string]string{"Content-Type": "image/png"},
+		MultiValueHeaders: map[string][]string{"Set-Cookie": {"a=1", "b=2"}},
+		Body:              "iVBORw0KGgo=",
+		IsBase64Encoded:   true,
+	}
+	apiGateway := ALBToAPIGatewayResponse(alb)
+	if apiGateway.StatusCode != alb.StatusCode || apiGateway.Body != alb.Body || !apiGateway.IsBase64Encoded ||
+		!reflect.DeepEqual(apiGateway.Headers, alb.Headers) || !reflect.DeepEqual(apiGateway.MultiValueHeaders, alb.MultiValueHeaders) {
+		t.Errorf("ALB to API Gateway: got %+v", apiGateway)
+
+	}
+	if back := APIGatewayToALBResponse(apiGateway); !reflect.DeepEqual(back, alb) {
+		t.Errorf("ALB round trip: got %+v, want %+v", back, alb)
+
+	}
+
+	apiGateway.MultiValueHeaders["Set-Cookie"][0] = "c=3"
+	if alb.MultiValueHeaders["Set-Cookie"][0] != "a=1" {
+		t.Errorf("converted response shares its headers with the original")
+
+	}
+
+	apiGateway = events.APIGatewayProxyResponse{
+		StatusCode:        http.StatusNotFound,
+		MultiValueHeaders: map[string][]string{"Vary": {"Accept", "Origin"}},
+		Body:              "AAEC",
+		IsBase64Encoded:   true,
+	}
+	alb = APIGatewayToALBResponse(apiGateway)
+	if alb.StatusDescription != description(http.StatusNotFound) {
+		t.Errorf("got status description %q, want %q", alb.StatusDescription, description(http.StatusNotFound))
+
+	}
+	if back := ALBToAPIGatewayResponse(alb); !reflect.DeepEqual(back, apiGateway) {
+		t.Errorf("API Gateway round trip: got %+v, want %+v", back, apiGateway)
+
+	}
+
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrDeadlineExceeded is returned when the handler did not respond before the
+// invocation deadline minus the buffer set with WithDeadlineBuffer.
+var ErrDeadlineExceeded = errors.New("handler did not respond before the invocation deadline")
+
+// withDeadlineBuffer returns a copy of ctx whose deadline is the one of the
+// invocation minus the buffer set with WithDeadlineBuffer.
+func (r *settings) withDeadlineBuffer(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if r.deadlineBuffer <= 0 || !ok {
+		return ctx, func() {}
+
+	}
+	return context.WithDeadline(ctx, deadline.Add(-r.deadlineBuffer))
+
+}
+
+// serveBeforeDeadline serves req with h like serveHTTP, but gives up with
+// ErrDeadlineExceeded once the context of req is done, leaving the time
+// before the invocation deadline to answer with a 504. Panics of the handler
+// are raised again in the calling goroutine. finish is called once h has
+// returned: after serveBeforeDeadline returns when h outlives the deadline,
+// since Go offers no way to stop it, so that the resources h uses are only
+// released when it no longer does.
+func (r *settings) serveBeforeDeadline(h http.Handler, w http.ResponseWriter, req *http.Request, finish func()) error {
+	if _, ok := req.Context().Deadline(); !ok || r.deadlineBuffer <= 0 {
+		defer finish()
+		return r.serveHTTP(h, w, req)
+
+	}
+
+	type result struct {
+		err   error
+		panic interface{}
+	}
+	done := make(chan result, 1)
+	go func() {
+		var res result
+		defer func() {
+			finish()
+			done <- res
+
+		}()
+		defer func() {
+			res.panic = recover()
+
+		}()
+		res.err = r.serveHTTP(h, w, req)
+
+	}()
+
+	select {
+	case res := <-done:
+		if res.panic != nil {
+			panic(res.panic)
+
+		}
+		return res.err
+
+	case <-req.Context().Done():
+		return ErrDeadlineExceeded
+
+	}
+
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxDecompressionRatio is the default maximum ratio between the
+// decompressed and the compressed size of a request body.
+const DefaultMaxDecompressionRatio = 100
+
+// DefaultMaxDecompressedBodySize is the default absolute cap on the size of a
+// decompressed request body.
+const DefaultMaxDecompressedBodySize = 64 << 20
+
+// ErrDecompressionLimit is returned when a compressed request body expands
+// beyond the configured ratio or absolute cap.
+var ErrDecompressionLimit = errors.New("decompressed body exceeds the configured limit")
+
+// decompressionLimit returns the maximum number of bytes a body of
+// compressedSize bytes may expand to.
+func (r *settings) decompressionLimit(compressedSize int) int64 {
+	ratio := r.maxDecompressionRatio
+	if ratio <= 0 {
+		ratio = DefaultMaxDecompressionRatio
+
+	}
+	max := r.maxDecompressedBodySize
+	if max <= 0 {
+		max = DefaultMaxDecompressedBodySize
+
+	}
+
+	limit := int64(compressedSize) * int64(ratio)
+	if limit > max {
+		limit = max
+
+	}
+	return limit
+
+}
+
+// gunzip decompresses a gzip encoded body, stopping as soon as the output
+// goes over the decompression limit.
+func (r *settings) gunzip(body []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+
+	}
+	defer zr.Close()
+	return readAllLimited(zr, r.decompressionLimit(len(body)))
+
+}
+
+// inflate decompresses a deflate encoded body, which HTTP defines as zlib
+// data, stopping as soon as the output goes over the decompression limit.
+func (r *settings) inflate(body []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+
+	}
+	defer zr.Close()
+	return readAllLimited(zr, r.decompressionLimit(len(body)))
+
+}
+
+// decompressBody replaces the gzip or deflate encoded body of req with its
+// decompressed form and removes the Content-Encoding header. Bodies with
+// other encodings, such as br, are left as they are.
+func (r *settings) decompressBody(req *http.Request, body io.Reader) error {
+	var decompress func([]byte) ([]byte, error)
+	switch strings.ToLower(strings.TrimSpace(req.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		decompress = r.gunzip
+
+	case "deflate":
+		decompress = r.inflate
+
+	default:
+		return nil
+
+	}
+
+	compressed, err := io.ReadAll(body)
+	if err != nil {
+		return badRequest("invalid request body: " + err.Error())
+
+	}
+	decompressed, err := decompress(compressed)
+	if errors.Is(err, ErrDecompressionLimit) {
+		return &HTTPError{StatusCode: http.StatusRequestEntityTooLarge, Message: err.Error()}
+
+	}
+	if err != nil {
+		return badRequest("invalid compressed request body: " + err.Error())
+
+	}
+
+	req.Header.Del("Content-Encoding")
+	req.Header.Del("Content-Length")
+	req.ContentLength = int64(len(decompressed))
+	req.Body = io.NopCloser(bytes.NewReader(decompressed))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(decompressed)), nil
+
+	}
+	return nil
+
+}
+
+// readAllLimited reads src until EOF and fails with ErrDecompressionLimit
+// when more than limit bytes are produced. It never reads more than
+// limit+1 bytes from src.
+func readAllLimited(src io.Reader, limit int64) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(src, limit+1))
+	if err != nil {
+		return nil, err
+
+	}
+	if int64(len(b)) > limit {
+		return nil, ErrDecompressionLimit
+
+	}
+	return b, nil
+
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func gzipped(t *testing.T, body []byte) string {
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	if _, err := zw.Write(body); err != nil {
+		t.Fatal(err)
+
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+
+	}
+	return base64.StdEncoding.EncodeToString(b.Bytes())
+
+}
+
+func TestDecompressionLimits(t *testing.T) {
+	bomb := gzipped(t, make([]byte, 1<<20))
+	small := gzipped(t, []byte("hello"))
+	tests := []struct {
+		name       string
+		opts       []Option
+		body       string
+		wantStatus int
+	}{
+		{name: "within limits", body: small, wantStatus: http.StatusOK},
+		{name: "over ratio", opts: []Option{WithMaxDecompressionRatio(10)}, body: bomb, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "over cap", opts: []Option{WithMaxDecompressionRatio(1 << 20), WithMaxDecompressedBodySize(1 << 10)}, body: bomb, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []byte
+			h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				got, _ = io.ReadAll(req.Body)
+				w.WriteHeader(http.StatusOK)
+
+			})
+			req := events.ALBTargetGroupRequest{
+				HTTPMethod:      http.MethodPost,
+				Path:            "/",
+				Headers:         map[string]string{"content-encoding": "gzip"},
+				Body:            tt.body,
+				IsBase64Encoded: true,
+			}
+			r := NewRequestAccessor(append(tt.opts, WithRequestDecompression())...)
+			resp, err := r.Handle(context.Background(), req, h)
+			if err != nil || resp.StatusCode != tt.wantStatus {
+				t.Fatalf("got %d, %v, want %d", resp.StatusCode, err, tt.wantStatus)
+
+			}
+			if tt.wantStatus == http.StatusOK && string(got) != "hello" {
+				t.Errorf("handler read %q, want hello", got)
+
+			}
+			if tt.wantStatus != http.StatusOK && got != nil {
+				t.Errorf("handler called with a %d byte body", len(got))
+
+			}
+
+		})
+
+	}
+
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// eventProbe holds the fields telling the supported events apart.
+type eventProbe struct {
+	Version        string `json:"version"`
+	HTTPMethod     string `json:"httpMethod"`
+	RawPath        string `json:"raw_path"`
+	RequestContext struct {
+		ServiceARN string          `json:"serviceArn"`
+		ELB        json.RawMessage `json:"elb"`
+		HTTP       json.RawMessage `json:"http"`
+		DomainName string          `json:"domainName"`
+	} `json:"requestContext"`
+	Records []struct {
+		CF json.RawMessage `json:"cf"`
+	} `json:"Records"`
+}
+
+// HandleRaw detects whether payload is an ALB, API Gateway REST API, API
+// Gateway HTTP API, Function URL, Lambda@Edge or VPC Lattice event, serves it
+// with h like Handle and returns the response type of the detected trigger.
+// It lets one function be attached to several triggers without code changes.
+func (r *settings) HandleRaw(ctx context.Context, payload json.RawMessage, h http.Handler) (interface{}, error) {
+	return r.handleRawWith(ctx, payload, func(ctx context.Context, req event) (events.ALBTargetGroupResponse, error) {
+		return r.handle(ctx, req, h)
+
+	})
+
+}
+
+// HandleRawDirect detects the type of payload like HandleRaw and serves it
+// with h like HandleDirect.
+func (r *settings) HandleRawDirect(ctx context.Context, payload json.RawMessage, h DirectHandler) (interface{}, error) {
+	return r.handleRawWith(ctx, payload, func(ctx context.Context, req event) (events.ALBTargetGroupResponse, error) {
+		return r.handleDirect(ctx, req, h)
+
+	})
+
+}
+
+// handleRawWith detects the type of payload and serves it with handle.
+func (r *settings) handleRawWith(ctx context.Context, payload json.RawMessage, handle func(context.Context, event) (events.ALBTargetGroupResponse, error)) (interface{}, error) {
+	if r.warmup && isWarmupPayload(payload) {
+		return warmupResponse(), nil
+
+	}
+
+	var probe eventProbe
+	if err := json.Unmarshal(payload, &probe); err != nil {
+		return nil, NewLoggedError("Could not decode event: %v", err)
+
+	}
+
+	switch {
+	case len(probe.RequestContext.ELB) > 0:
+		return handleRaw[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](ctx, payload, handle)
+
+	case len(probe.RequestContext.HTTP) > 0 && strings.Contains(probe.RequestContext.DomainName, ".lambda-url."):
+		return handleRaw[events.LambdaFunctionURLRequest, events.LambdaFunctionURLResponse](ctx, payload, handle)
+
+	case len(probe.RequestContext.HTTP) > 0:
+		return handleRaw[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse](ctx, payload, handle)
+
+	case probe.RequestContext.ServiceARN != "":
+		return handleRaw[VPCLatticeRequestV2, VPCLatticeResponse](ctx, payload, handle)
+
+	case probe.RawPath != "":
+		return handleRaw[VPCLatticeRequest, VPCLatticeResponse](ctx, payload, handle)
+
+	case probe.HTTPMethod != "":
+		return handleRaw[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](ctx, payload, handle)
+
+	case len(probe.Records) > 0 && len(probe.Records[0].CF) > 0:
+		return handleRaw[CloudFrontEvent, CloudFrontResponse](ctx, payload, handle)
+
+	}
+	return nil, NewLoggedError("Could not detect the type of event, version %q", probe.Version)
+
+}
+
+// handleRaw decodes payload as an E and serves it with handle.
+func handleRaw[E Event, R Response](ctx context.Context, payload json.RawMessage, handle func(context.Context, event) (events.ALBTargetGroupResponse, error)) (interface{}, error) {
+	var req E
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, NewLoggedError("Could not decode event: %v", err)
+
+	}
+	resp, err := handle(ctx, newEvent(req))
+	return FromALBResponse[R](resp), err
+
+}
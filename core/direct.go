@@ -0,0 +1,233 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// DirectRequest is an event converted for the adapters of frameworks that do
+// not build on net/http, such as fasthttp, so that they build their own
+// request from it without an http.Request in between.
+type DirectRequest struct {
+	Method string
+	// Path is the percent-encoded path, stripped of the base path.
+	Path string
+	// RawQuery is the encoded query string, without the leading ?.
+	RawQuery string
+	// Host is the host the request was sent to, promoted from the Host
+	// header like on the requests built by EventToRequest.
+	Host   string
+	Header http.Header
+	// Body is the decoded body.
+	Body []byte
+	// ClientIP is the address of the client, as ClientIP reports it.
+	ClientIP string
+	// Context carries the same values as the context of the requests built
+	// by EventToRequestWithContext.
+	Context context.Context
+}
+
+// RequestURI returns the path and query string of req.
+func (req *DirectRequest) RequestURI() string {
+	if req.RawQuery == "" {
+		return req.Path
+
+	}
+	return req.Path + "?" + req.RawQuery
+
+}
+
+// DirectHandler serves a DirectRequest and writes its response into w.
+type DirectHandler func(req *DirectRequest, w *ProxyResponseWriter)
+
+// HandleDirect converts an event into a DirectRequest, serves it with h and
+// converts what h wrote into a response of type R, like Handle does with an
+// http.Handler. The options working on the http.Request, WithBodyValidator,
+// WithMethodOverride, WithRequestDecompression, WithBodyChecksum,
+// WithContentMD5 and WithALBMutualTLS, do not apply, and WithDeadlineBuffer
+// only brings the deadline of the request context forward.
+func (r *Accessor[E, R]) HandleDirect(ctx context.Context, req E, h DirectHandler) (R, error) {
+	resp, err := r.handleDirect(ctx, newEvent(req), h)
+	return FromALBResponse[R](resp), err
+
+}
+
+// StreamDirect serves an event like HandleDirect and returns the response as
+// a streaming response, for functions behind a Function URL in
+// RESPONSE_STREAM mode. Since h writes whole responses, the body is only sent
+// once h has returned.
+func (r *Accessor[E, R]) StreamDirect(ctx context.Context, req E, h DirectHandler) (*events.LambdaFunctionURLStreamingResponse, error) {
+	resp, err := r.handleDirect(ctx, newEvent(req), h)
+	if err != nil {
+		return nil, err
+
+	}
+	return bufferedStreamingResponse(resp), nil
+
+}
+
+// handleDirect serves an event with h once the maintenance mode and the
+// concurrency limit let it through.
+func (r *settings) handleDirect(ctx context.Context, req event, h DirectHandler) (events.ALBTargetGroupResponse, error) {
+	resp, release, ok := r.admit(ctx, req)
+	if !ok {
+		return resp, nil
+
+	}
+	defer release()
+
+	start := time.Now()
+	ctx, cancel := r.withDeadlineBuffer(ctx)
+	defer cancel()
+	directRequest, err := r.newDirectRequest(ctx, req)
+	if err != nil {
+		r.logRequestError(req.HTTPMethod, req.Path, 0, err)
+
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		resp := r.errorResponse(httpErr)
+		r.observe(resp.StatusCode, start, eventBodySize(req), len(resp.Body))
+		return resp, nil
+
+	}
+	if err != nil {
+		r.observe(http.StatusGatewayTimeout, start, eventBodySize(req), 0)
+		return TimeoutResponse(), NewLoggedError("Could not convert proxy event to request: %v", err)
+
+	}
+
+	// The path was validated by newDirectRequest.
+	path, _ := url.PathUnescape(directRequest.Path)
+	requestBytes := int64(len(directRequest.Body))
+	respWriter := r.NewResponseWriter(path)
+	respWriter.headerNames = r.headerNames(req)
+	if err := r.serveDirect(h, directRequest, respWriter); err != nil {
+		r.logRequestError(directRequest.Method, path, http.StatusInternalServerError, err)
+		r.observe(http.StatusInternalServerError, start, requestBytes, 0)
+		return statusResponse(http.StatusInternalServerError), err
+
+	}
+	if r.defaultOptions && directRequest.Method == http.MethodOptions {
+		respWriter = r.defaultOptionsResponse(path, respWriter)
+
+	}
+
+	proxyResponse, err := respWriter.GetProxyResponse()
+	if err != nil {
+		r.observe(http.StatusGatewayTimeout, start, requestBytes, 0)
+		return TimeoutResponse(), NewLoggedError("Error while generating proxy response: %v", err)
+
+	}
+	headerCount := 0
+	for _, values := range directRequest.Header {
+		headerCount += len(values)
+
+	}
+	entry := AccessLogEntry{
+		Method:             directRequest.Method,
+		Path:               path,
+		Query:              directRequest.RawQuery,
+		Status:             respWriter.Status(),
+		Duration:           time.Since(start),
+		RequestHeaderCount: headerCount,
+		RequestBodySize:    requestBytes,
+		ResponseBodySize:   respWriter.Size(),
+	}
+	r.logAccess(entry)
+	r.emitEMF(entry)
+	r.observe(respWriter.Status(), start, requestBytes, respWriter.Size())
+
+	return proxyResponse, nil
+
+}
+
+// serveDirect serves req with h unless the rate limiter denies it. With
+// WithPanicRecovery, a panic of h is returned as a *PanicError.
+func (r *settings) serveDirect(h DirectHandler, req *DirectRequest, w *ProxyResponseWriter) (err error) {
+	if r.recoverPanics {
+		defer func() {
+			if v := recover(); v != nil {
+				err = &PanicError{Value: v, Stack: debug.Stack()}
+
+			}
+
+		}()
+
+	}
+	if r.rateLimiter != nil && r.denyRateLimited(w, req.Header, req.ClientIP) {
+		return nil
+
+	}
+	h(req, w)
+	return nil
+
+}
+
+// newDirectRequest converts an event into a DirectRequest carrying the
+// request values of ctx and the event.
+func (r *settings) newDirectRequest(ctx context.Context, req event) (*DirectRequest, error) {
+	if err := r.checkEvent(req); err != nil {
+		return nil, err
+
+	}
+	body, err := decodeEventBody(req)
+	if err != nil {
+		return nil, err
+
+	}
+	path, err := r.eventPath(req)
+	if err != nil {
+		return nil, err
+
+	}
+	if _, err := url.PathUnescape(path); err != nil {
+		return nil, err
+
+	}
+
+	header := req.header()
+	principal, err := r.albPrincipal(ctx, header)
+	if err != nil {
+		return nil, err
+
+	}
+	directRequest := &DirectRequest{
+		Method:   strings.ToUpper(req.HTTPMethod),
+		Path:     path,
+		RawQuery: eventQueryString(req, r.rawQueryString && req.isALB()),
+		Header:   header,
+		Body:     body,
+	}
+	serverAddress, hasCustomAddress := r.targetAddress()
+	if u, err := url.Parse(serverAddress); err == nil {
+		directRequest.Host = u.Host
+
+	}
+	if !hasCustomAddress && !r.syntheticHost {
+		// Like net/http servers, promote the Host header to the request host.
+		host := header.Get("Host")
+		if host == "" {
+			host = req.domainName()
+
+		}
+		if host != "" {
+			directRequest.Host = host
+			header.Del("Host")
+
+		}
+
+	}
+	directRequest.ClientIP = r.headerConnectionInfo(header, "", directRequest.Host).clientIP
+	directRequest.Context = r.newRequestContext(ctx, req, header, principal)
+	restoreHeaderCase(header, r.headerNames(req))
+	return directRequest, nil
+
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandleDirect(t *testing.T) {
+	var got DirectRequest
+	h := func(req *DirectRequest, w *ProxyResponseWriter) {
+		got = *req
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(append([]byte("got "), req.Body...))
+
+	}
+	req := events.ALBTargetGroupRequest{
+		HTTPMethod:                      "post",
+		Path:                            "/api/files/a%2Fb",
+		MultiValueQueryStringParameters: map[string][]string{"id": {"42"}},
+		MultiValueHeaders: map[string][]string{
+			"host":            {"example.org"},
+			"x-forwarded-for": {"198.51.100.1, 203.0.113.9"},
+		},
+		Body:            "aGVsbG8=",
+		IsBase64Encoded: true,
+		RequestContext:  events.ALBTargetGroupRequestContext{ELB: events.ELBContext{TargetGroupArn: "arn"}},
+	}
+
+	resp, err := NewRequestAccessor(WithStripBasePath("/api")).HandleDirect(context.Background(), req, h)
+	if err != nil {
+		t.Fatal(err)
+
+	}
+	if got.Method != http.MethodPost || got.RequestURI() != "/files/a%2Fb?id=42" || got.Host != "example.org" || got.ClientIP != "203.0.113.9" {
+		t.Errorf("got %s %s to %s from %s", got.Method, got.RequestURI(), got.Host, got.ClientIP)
+
+	}
+	if got.Header.Get("Host") != "" || got.Header.Get("X-Forwarded-For") == "" {
+		t.Errorf("got headers %v", got.Header)
+
+	}
+	if alb, ok := GetALBContextFromContext(got.Context); !ok || alb.ELB.TargetGroupArn != "arn" {
+		t.Errorf("got ALB context %+v, %v", alb, ok)
+
+	}
+	want := events.ALBTargetGroupResponse{
+		StatusCode:        http.StatusCreated,
+		StatusDescription: "201",
+		Body:              "got hello",
+		MultiValueHeaders: map[string][]string{"Content-Type": {"text/plain"}},
+	}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("got %+v, want %+v", resp, want)
+
+	}
+
+}
+
+func TestHandleDirectTooLarge(t *testing.T) {
+	called := false
+	h := func(req *DirectRequest, w *ProxyResponseWriter) {
+		called = true
+
+	}
+	req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodPost, Path: "/", Body: "hello"}
+	resp, err := NewRequestAccessor(WithMaxRequestBodySize(4)).HandleDirect(context.Background(), req, h)
+	if err != nil || resp.StatusCode != http.StatusRequestEntityTooLarge || called {
+		t.Errorf("got %d, %v, called %v, want 413 without calling the handler", resp.StatusCode, err, called)
+
+	}
+
+}
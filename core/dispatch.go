@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Dispatcher routes the payloads of a function attached to several triggers
+// to the handler of their event source. HTTP events go to HTTP, which is
+// usually the ProxyRawWithContext method of a framework adapter, and queue
+// and pub/sub events to the ProxyWithContext method of the matching bridge
+// adapter:
+//
+//	d := &core.Dispatcher{HTTP: echoLambda.ProxyRawWithContext, SQS: sqsLambda.ProxyWithContext}
+//	lambda.Start(d.Invoke)
+//
+// Events whose handler is not set are rejected with an error.
+type Dispatcher struct {
+	HTTP        func(context.Context, json.RawMessage) (interface{}, error)
+	SQS         func(context.Context, events.SQSEvent) (events.SQSEventResponse, error)
+	SNS         func(context.Context, events.SNSEvent) error
+	EventBridge func(context.Context, events.CloudWatchEvent) error
+}
+
+// dispatchProbe holds the fields telling event sources apart. Field names are
+// matched case-insensitively, which covers both the eventSource of SQS
+// records and the EventSource of SNS records.
+type dispatchProbe struct {
+	Records []struct {
+		EventSource string `json:"eventSource"`
+	} `json:"Records"`
+	DetailType *string `json:"detail-type"`
+	Source     string  `json:"source"`
+}
+
+// Invoke decodes payload according to its event source and calls the handler
+// set for it. It returns what the handler returned.
+func (d *Dispatcher) Invoke(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	var probe dispatchProbe
+	if err := json.Unmarshal(payload, &probe); err != nil {
+		return nil, NewLoggedError("Could not decode event: %v", err)
+
+	}
+
+	source := "http"
+	if len(probe.Records) > 0 && probe.Records[0].EventSource != "" {
+		source = probe.Records[0].EventSource
+
+	} else if probe.DetailType != nil && probe.Source != "" {
+		source = "eventbridge"
+
+	}
+
+	switch source {
+	case "aws:sqs":
+		if d.SQS == nil {
+			break
+
+		}
+		var ev events.SQSEvent
+		if err := json.Unmarshal(payload, &ev); err != nil {
+			return nil, NewLoggedError("Could not decode SQS event: %v", err)
+
+		}
+		return d.SQS(ctx, ev)
+
+	case "aws:sns":
+		if d.SNS == nil {
+			break
+
+		}
+		var ev events.SNSEvent
+		if err := json.Unmarshal(payload, &ev); err != nil {
+			return nil, NewLoggedError("Could not decode SNS event: %v", err)
+
+		}
+		return nil, d.SNS(ctx, ev)
+
+	case "eventbridge":
+		if d.EventBridge == nil {
+			break
+
+		}
+		var ev events.CloudWatchEvent
+		if err := json.Unmarshal(payload, &ev); err != nil {
+			return nil, NewLoggedError("Could not decode EventBridge event: %v", err)
+
+		}
+		return nil, d.EventBridge(ctx, ev)
+
+	case "http":
+		if d.HTTP == nil {
+			break
+
+		}
+		return d.HTTP(ctx, payload)
+
+	}
+	return nil, NewLoggedError("No handler for %s events", source)
+
+}
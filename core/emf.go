@@ -0,0 +1,84 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// emfMetric is a metric definition of the CloudWatch Embedded Metric Format.
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+// emfDirective tells CloudWatch which members of the log line are metrics
+// and which are dimensions.
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+// emfRecord is a single Embedded Metric Format log line.
+type emfRecord struct {
+	AWS              emfMetadata `json:"_aws"`
+	Method           string      `json:"Method"`
+	StatusClass      string      `json:"StatusClass"`
+	Latency          float64     `json:"Latency"`
+	RequestBodySize  int64       `json:"RequestBodySize"`
+	ResponseBodySize int         `json:"ResponseBodySize"`
+	Count            int         `json:"Count"`
+}
+
+// emitEMF logs the metrics of the served request described by entry in the
+// CloudWatch Embedded Metric Format when WithEMFMetrics is set.
+func (r *settings) emitEMF(entry AccessLogEntry) {
+	if r.emfNamespace == "" {
+		return
+
+	}
+
+	record := emfRecord{
+		AWS: emfMetadata{
+			Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
+			CloudWatchMetrics: []emfDirective{{
+				Namespace:  r.emfNamespace,
+				Dimensions: [][]string{{"Method", "StatusClass"}},
+				Metrics: []emfMetric{
+					{Name: "Latency", Unit: "Milliseconds"},
+					{Name: "RequestBodySize", Unit: "Bytes"},
+					{Name: "ResponseBodySize", Unit: "Bytes"},
+					{Name: "Count", Unit: "Count"},
+				},
+			}},
+		},
+		Method:           entry.Method,
+		StatusClass:      fmt.Sprintf("%dxx", entry.Status/100),
+		Latency:          float64(entry.Duration) / float64(time.Millisecond),
+		RequestBodySize:  entry.RequestBodySize,
+		ResponseBodySize: entry.ResponseBodySize,
+		Count:            1,
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		r.log().Printf("could not encode EMF metrics: %v", err)
+		return
+
+	}
+
+	// CloudWatch only extracts metrics from log lines that are pure JSON, so
+	// the line bypasses the prefixed standard logger unless one was set.
+	if r.logger == nil {
+		fmt.Println(string(line))
+		return
+
+	}
+	r.logger.Printf("%s", line)
+
+}
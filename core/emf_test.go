@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// lineLogger is a Logger keeping the lines it logs.
+type lineLogger []string
+
+func (l *lineLogger) Printf(format string, v ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, v...))
+
+}
+
+func TestEMFMetrics(t *testing.T) {
+	var lines lineLogger
+	r := NewRequestAccessor(WithLogger(&lines), WithEMFMetrics("MyService"))
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "ok")
+
+	})
+	req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodPost, Path: "/items", Body: "hello"}
+	if _, err := r.Handle(context.Background(), req, h); err != nil {
+		t.Fatal(err)
+
+	}
+	if len(lines) != 1 {
+		t.Fatalf("got %d log lines, want 1: %q", len(lines), lines)
+
+	}
+
+	var record struct {
+		AWS struct {
+			Timestamp         int64
+			CloudWatchMetrics []struct {
+				Namespace  string
+				Dimensions [][]string
+				Metrics    []struct{ Name, Unit string }
+			}
+		} `json:"_aws"`
+		Method           string
+		StatusClass      string
+		Latency          *float64
+		RequestBodySize  int64
+		ResponseBodySize int
+		Count            int
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("invalid EMF line %q: %v", lines[0], err)
+
+	}
+	if record.AWS.Timestamp == 0 || len(record.AWS.CloudWatchMetrics) != 1 {
+		t.Fatalf("invalid _aws metadata: %s", lines[0])
+
+	}
+	directive := record.AWS.CloudWatchMetrics[0]
+	if directive.Namespace != "MyService" || !reflect.DeepEqual(directive.Dimensions, [][]string{{"Method", "StatusClass"}}) {
+		t.Errorf("got namespace %q and dimensions %v, want MyService and [[Method StatusClass]]", directive.Namespace, directive.Dimensions)
+
+	}
+	var names []string
+	for _, metric := range directive.Metrics {
+		names = append(names, metric.Name)
+
+	}
+	if !reflect.DeepEqual(names, []string{"Latency", "RequestBodySize", "ResponseBodySize", "Count"}) {
+		t.Errorf("got metrics %v", names)
+
+	}
+	if record.Method != http.MethodPost || record.StatusClass != "2xx" || record.Latency == nil ||
+		record.RequestBodySize != 5 || record.ResponseBodySize != 2 || record.Count != 1 {
+		t.Errorf("got members %s", lines[0])
+
+	}
+
+}
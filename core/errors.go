@@ -0,0 +1,63 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// HTTPError is returned by the event conversion when the event describes a
+// request the client got wrong. RequestAccessor.Handle answers it with a
+// response of the given status code instead of failing the invocation.
+type HTTPError struct {
+	StatusCode int
+	Message    string
+	// Errors, when set, are returned as a JSON body listing them.
+	Errors []FieldError
+	// Header holds additional response headers, such as Location.
+	Header http.Header
+}
+
+func (e *HTTPError) Error() string {
+	return e.Message
+
+}
+
+// errorResponse returns a plain text response describing err, or a JSON one
+// listing its field errors.
+func (r *settings) errorResponse(err *HTTPError) events.ALBTargetGroupResponse {
+	resp := statusResponse(err.StatusCode)
+	resp.Body = err.Message
+	contentType := "text/plain; charset=utf-8"
+	if len(err.Errors) > 0 {
+		body, _ := json.Marshal(validationErrorBody{Errors: err.Errors})
+		resp.Body = string(body)
+		contentType = "application/json"
+
+	}
+	if r.singleValueHeaders || r.dualHeaders {
+		resp.Headers = map[string]string{contentTypeHeaderKey: contentType}
+		for name := range err.Header {
+			resp.Headers[name] = err.Header.Get(name)
+
+		}
+
+	}
+	if !r.singleValueHeaders {
+		resp.MultiValueHeaders = map[string][]string{contentTypeHeaderKey: {contentType}}
+		for name, values := range err.Header {
+			resp.MultiValueHeaders[name] = values
+
+		}
+
+	}
+	return resp
+
+}
+
+// badRequest returns an *HTTPError with the 400 status code.
+func badRequest(message string) *HTTPError {
+	return &HTTPError{StatusCode: http.StatusBadRequest, Message: message}
+
+}
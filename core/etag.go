@@ -0,0 +1,79 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const etagHeaderKey = "ETag"
+
+// ETagMiddleware buffers the response of next and, when the handler did not
+// set an ETag itself, adds a weak ETag computed from a hash of the body.
+// GET and HEAD requests whose If-None-Match header matches the ETag receive
+// an empty 304 Not Modified instead of the body.
+func ETagMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		buffered := NewProxyResponseWriter()
+		next.ServeHTTP(buffered, req)
+
+		status := buffered.status
+		if status == defaultStatusCode {
+			status = http.StatusOK
+
+		}
+		header := w.Header()
+		for k, v := range buffered.headers {
+			header[k] = v
+
+		}
+
+		cacheable := status == http.StatusOK && (req.Method == http.MethodGet || req.Method == http.MethodHead)
+		etag := header.Get(etagHeaderKey)
+		if etag == "" && cacheable {
+			etag = weakETag(buffered.body.Bytes())
+			header.Set(etagHeaderKey, etag)
+
+		}
+
+		if cacheable && etagMatches(req.Header.Get("If-None-Match"), etag) {
+			header.Del(contentTypeHeaderKey)
+			header.Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+
+		}
+
+		w.WriteHeader(status)
+		w.Write(buffered.body.Bytes())
+
+	})
+
+}
+
+// weakETag returns a weak entity tag derived from the body content.
+func weakETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag
+// using the weak comparison function from RFC 7232.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+
+		}
+
+	}
+	return false
+
+}
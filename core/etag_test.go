@@ -0,0 +1,60 @@
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestETagMiddleware(t *testing.T) {
+	h := ETagMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, "hello")
+
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || rec.Body.String() != "hello" || !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("got %d %q with ETag %q, want 200 hello with a weak ETag", rec.Code, rec.Body.String(), etag)
+
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 || rec.Header().Get("ETag") != etag {
+		t.Errorf("If-None-Match: got %d %q with ETag %q, want an empty 304 with ETag %q", rec.Code, rec.Body.String(), rec.Header().Get("ETag"), etag)
+
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `W/"other"`)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "hello" {
+		t.Errorf("other If-None-Match: got %d %q, want 200 hello", rec.Code, rec.Body.String())
+
+	}
+
+}
+
+func TestETagMiddlewareKeepsHandlerETag(t *testing.T) {
+	h := ETagMiddleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, "hello")
+
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `W/"v1"`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Header().Get("ETag") != `"v1"` {
+		t.Errorf("got %d with ETag %q, want 304 with ETag \"v1\"", rec.Code, rec.Header().Get("ETag"))
+
+	}
+
+}
@@ -0,0 +1,219 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// eventSource identifies the trigger an event was received from.
+type eventSource int
+
+// The zero eventSource is the one of events built from HTTPEvent
+// implementations, which are never assumed to come from a known trigger.
+const (
+	sourceCustom eventSource = iota
+	sourceALB
+	sourceAPIGateway
+	sourceHTTPAPI
+	sourceFunctionURL
+	sourceCloudFront
+	sourceLattice
+)
+
+// event is the form every Event takes before being converted into an
+// http.Request. The fields of the ALB event are the ones all the triggers
+// share; the others hold what is specific to some of them.
+type event struct {
+	events.ALBTargetGroupRequest
+
+	source             eventSource
+	rawQuery           string
+	stage              string
+	pathParameters     map[string]string
+	stageVariables     map[string]string
+	apiGatewayContext  *events.APIGatewayProxyRequestContext
+	httpAPIContext     *events.APIGatewayV2HTTPRequestContext
+	functionURLContext *events.LambdaFunctionURLRequestContext
+	cloudFrontConfig   *CloudFrontConfig
+	bodyTruncated      bool
+	latticeContext     *VPCLatticeRequestContext
+	raw                interface{}
+}
+
+// isALB reports whether the event was received from an ALB target group.
+func (req event) isALB() bool {
+	return req.source == sourceALB
+}
+
+// domainName returns the domain name the request was received on according
+// to the event request context, for events that have one.
+func (req event) domainName() string {
+	switch {
+	case req.apiGatewayContext != nil:
+		return req.apiGatewayContext.DomainName
+
+	case req.httpAPIContext != nil:
+		return req.httpAPIContext.DomainName
+
+	case req.functionURLContext != nil:
+		return req.functionURLContext.DomainName
+
+	}
+	return ""
+
+}
+
+// header returns the headers of the event, taken from the multi-value headers
+// when the event has them so that repeated headers, such as several Cookie
+// headers or X-Forwarded-For hops, are all kept.
+func (req event) header() http.Header {
+	header := make(http.Header)
+	if len(req.MultiValueHeaders) > 0 {
+		for name, values := range req.MultiValueHeaders {
+			for _, v := range values {
+				header.Add(name, v)
+
+			}
+
+		}
+		return header
+
+	}
+	for name, value := range req.Headers {
+		header.Add(name, value)
+
+	}
+	return header
+
+}
+
+// newEvent converts req into an event.
+func newEvent[E Event](req E) event {
+	var ev event
+	switch e := any(req).(type) {
+	case events.ALBTargetGroupRequest:
+		ev = event{ALBTargetGroupRequest: e, source: sourceALB}
+
+	case events.APIGatewayProxyRequest:
+		ev = apiGatewayEvent(e)
+		ev.source = sourceAPIGateway
+
+	case events.APIGatewayV2HTTPRequest:
+		ev = httpAPIEvent(e)
+		ev.source = sourceHTTPAPI
+
+	case events.LambdaFunctionURLRequest:
+		ev = functionURLEvent(e)
+		ev.source = sourceFunctionURL
+
+	case CloudFrontEvent:
+		ev = cloudFrontEvent(e)
+		ev.source = sourceCloudFront
+
+	case VPCLatticeRequest:
+		ev = latticeEvent(e)
+		ev.source = sourceLattice
+
+	case VPCLatticeRequestV2:
+		ev = latticeEventV2(e)
+		ev.source = sourceLattice
+
+	default:
+		panic(fmt.Sprintf("core: unsupported event type %T", req))
+
+	}
+	ev.raw = req
+	return ev
+
+}
+
+// apiGatewayEvent converts an API Gateway REST API proxy event. Its path does
+// not include the stage, which is kept for GetStageFromContext.
+func apiGatewayEvent(req events.APIGatewayProxyRequest) event {
+	requestContext := req.RequestContext
+	return event{
+		ALBTargetGroupRequest: events.ALBTargetGroupRequest{
+			HTTPMethod:                      req.HTTPMethod,
+			Path:                            req.Path,
+			QueryStringParameters:           req.QueryStringParameters,
+			MultiValueQueryStringParameters: req.MultiValueQueryStringParameters,
+			Headers:                         req.Headers,
+			MultiValueHeaders:               req.MultiValueHeaders,
+			IsBase64Encoded:                 req.IsBase64Encoded,
+			Body:                            req.Body,
+		},
+		stage:             requestContext.Stage,
+		pathParameters:    req.PathParameters,
+		stageVariables:    req.StageVariables,
+		apiGatewayContext: &requestContext,
+	}
+
+}
+
+// httpAPIEvent converts an API Gateway HTTP API event, using the payload
+// format version 2.0. Its raw query string is kept as sent by the client and
+// its cookies are joined back into a Cookie header. The raw path of a named
+// stage starts with the stage name; remove it with StripBasePath.
+func httpAPIEvent(req events.APIGatewayV2HTTPRequest) event {
+	requestContext := req.RequestContext
+	return event{
+		ALBTargetGroupRequest: events.ALBTargetGroupRequest{
+			HTTPMethod:            requestContext.HTTP.Method,
+			Path:                  req.RawPath,
+			QueryStringParameters: req.QueryStringParameters,
+			Headers:               withCookies(req.Headers, req.Cookies),
+			IsBase64Encoded:       req.IsBase64Encoded,
+			Body:                  req.Body,
+		},
+		rawQuery:       req.RawQueryString,
+		stage:          requestContext.Stage,
+		pathParameters: req.PathParameters,
+		stageVariables: req.StageVariables,
+		httpAPIContext: &requestContext,
+	}
+
+}
+
+// functionURLEvent converts a Lambda Function URL event, which shares the
+// payload format of HTTP APIs minus the stages and routes.
+func functionURLEvent(req events.LambdaFunctionURLRequest) event {
+	requestContext := req.RequestContext
+	return event{
+		ALBTargetGroupRequest: events.ALBTargetGroupRequest{
+			HTTPMethod:            requestContext.HTTP.Method,
+			Path:                  req.RawPath,
+			QueryStringParameters: req.QueryStringParameters,
+			Headers:               withCookies(req.Headers, req.Cookies),
+			IsBase64Encoded:       req.IsBase64Encoded,
+			Body:                  req.Body,
+		},
+		rawQuery:           req.RawQueryString,
+		functionURLContext: &requestContext,
+	}
+
+}
+
+// withCookies returns a copy of headers with cookies joined back into a
+// Cookie header, after the value of the Cookie header if there is one.
+func withCookies(headers map[string]string, cookies []string) map[string]string {
+	copied := make(map[string]string, len(headers)+1)
+	var existing []string
+	for name, value := range headers {
+		if strings.EqualFold(name, "Cookie") {
+			existing = append(existing, value)
+			continue
+
+		}
+		copied[name] = value
+
+	}
+	if all := append(existing, cookies...); len(all) > 0 {
+		copied["cookie"] = strings.Join(all, "; ")
+
+	}
+	return copied
+
+}
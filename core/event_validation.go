@@ -0,0 +1,84 @@
+package core
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Limits enforced on the headers of events by WithStrictEventValidation.
+const (
+	maxEventHeaderCount = 100
+	maxEventHeaderBytes = 64 << 10
+)
+
+// validateEvent checks that an event describes a request that can be
+// converted and returns a 400 *HTTPError listing what is wrong otherwise.
+func validateEvent(req event) error {
+	var fieldErrors []FieldError
+	if req.HTTPMethod == "" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "httpMethod", Message: "missing method"})
+
+	} else if !isToken(req.HTTPMethod) {
+		fieldErrors = append(fieldErrors, FieldError{Field: "httpMethod", Message: "invalid method"})
+
+	}
+
+	if !strings.HasPrefix(req.Path, "/") {
+		fieldErrors = append(fieldErrors, FieldError{Field: "path", Message: "path must start with /"})
+
+	} else if err := validatePath(req.Path); err != nil {
+		fieldErrors = append(fieldErrors, FieldError{Field: "path", Message: err.Error()})
+
+	}
+
+	// The body is decoded as a stream so that it is never held decoded.
+	if req.IsBase64Encoded {
+		decoder := base64.NewDecoder(base64.StdEncoding, strings.NewReader(req.Body))
+		if _, err := io.Copy(io.Discard, decoder); err != nil {
+			fieldErrors = append(fieldErrors, FieldError{Field: "body", Message: "invalid base64 encoding"})
+
+		}
+
+	}
+
+	header := req.header()
+	count, size := 0, 0
+	for name, values := range header {
+		for _, v := range values {
+			count++
+			size += len(name) + len(v)
+
+		}
+
+	}
+	if count > maxEventHeaderCount {
+		fieldErrors = append(fieldErrors, FieldError{Field: "headers", Message: "too many headers"})
+
+	}
+	if size > maxEventHeaderBytes {
+		fieldErrors = append(fieldErrors, FieldError{Field: "headers", Message: "headers too large"})
+
+	}
+
+	if len(fieldErrors) == 0 {
+		return nil
+
+	}
+	return &HTTPError{StatusCode: http.StatusBadRequest, Message: "invalid event", Errors: fieldErrors}
+
+}
+
+// isToken reports whether s is an RFC 7230 token, the syntax of methods.
+func isToken(s string) bool {
+	for _, c := range s {
+		if c >= 0x7f || c <= ' ' || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", c) {
+			return false
+
+		}
+
+	}
+	return s != ""
+
+}
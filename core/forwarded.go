@@ -0,0 +1,198 @@
+package core
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// connectionInfo describes the connection of the client as reported by the
+// proxies in front of the function.
+type connectionInfo struct {
+	clientIP string
+	proto    string
+	host     string
+}
+
+// connectionInfo derives the client connection details from the X-Forwarded-*
+// headers. With WithForwardedHeaderParsing the values found in the RFC 7239
+// Forwarded header take precedence, and X-Forwarded-* only fill the gaps.
+// Since clients can send a Forwarded header of their own and ALB does not add
+// one, it is only read when the peer of the load balancer, the last
+// X-Forwarded-For hop, is one of the WithTrustedProxies.
+func (r *settings) connectionInfo(req *http.Request) connectionInfo {
+	return r.headerConnectionInfo(req.Header, req.RemoteAddr, req.Host)
+
+}
+
+// headerConnectionInfo is connectionInfo for the headers of a request sent
+// from remoteAddr to host.
+func (r *settings) headerConnectionInfo(header http.Header, remoteAddr string, host string) connectionInfo {
+	forwardedFor := header.Get("X-Forwarded-For")
+	info := connectionInfo{
+		clientIP: r.clientHop(forwardedFor),
+		proto:    lastHop(header.Get("X-Forwarded-Proto")),
+		host:     lastHop(header.Get("X-Forwarded-Host")),
+	}
+
+	if r.parseForwarded && r.isTrustedProxy(lastHop(forwardedFor)) {
+		forwarded := r.forwardedHop(strings.Join(header.Values("Forwarded"), ","))
+		// Obfuscated identifiers such as for=unknown are not addresses.
+		if net.ParseIP(forwarded.clientIP) != nil {
+			info.clientIP = forwarded.clientIP
+
+		}
+		if forwarded.proto != "" {
+			info.proto = forwarded.proto
+
+		}
+		if forwarded.host != "" {
+			info.host = forwarded.host
+
+		}
+
+	}
+
+	if info.clientIP == "" {
+		info.clientIP = stripPort(remoteAddr)
+
+	}
+	// The Host header is promoted to Request.Host on conversion.
+	if info.host == "" {
+		info.host = host
+
+	}
+	return info
+
+}
+
+// ClientIP returns the IP address of the client that sent req, as reported
+// by the proxies in front of the function.
+func (r *settings) ClientIP(req *http.Request) string {
+	return r.connectionInfo(req).clientIP
+
+}
+
+// ClientProto returns the protocol, http or https, the client used to send
+// req, as reported by the proxies in front of the function.
+func (r *settings) ClientProto(req *http.Request) string {
+	return r.connectionInfo(req).proto
+
+}
+
+// ClientHost returns the host the client sent req to, as reported by the
+// proxies in front of the function.
+func (r *settings) ClientHost(req *http.Request) string {
+	return r.connectionInfo(req).host
+
+}
+
+// forwardedHop returns the element of an RFC 7239 Forwarded header added by
+// the first trusted proxy the client went through: the last element, unless
+// its for= is a trusted proxy too, in which case the element before, and so on.
+func (r *settings) forwardedHop(header string) connectionInfo {
+	if header == "" {
+		return connectionInfo{}
+
+	}
+	elements := strings.Split(header, ",")
+	for i := len(elements) - 1; i > 0; i-- {
+		info := parseForwarded(elements[i])
+		if !r.isTrustedProxy(info.clientIP) {
+			return info
+
+		}
+
+	}
+	return parseForwarded(elements[0])
+
+}
+
+// parseForwarded parses an element of an RFC 7239 Forwarded header, such as
+// for=192.0.2.60;proto=https;host=example.com.
+func parseForwarded(element string) connectionInfo {
+	var info connectionInfo
+	for _, pair := range strings.Split(element, ";") {
+		eq := strings.Index(pair, "=")
+		if eq < 0 {
+			continue
+
+		}
+		key := strings.ToLower(strings.TrimSpace(pair[:eq]))
+		value := strings.Trim(strings.TrimSpace(pair[eq+1:]), `"`)
+		switch key {
+		case "for":
+			info.clientIP = stripPort(value)
+
+		case "proto":
+			info.proto = strings.ToLower(value)
+
+		case "host":
+			info.host = value
+
+		}
+
+	}
+	return info
+
+}
+
+// clientHop returns the entry of X-Forwarded-For added for the client: the
+// last one, unless it is a trusted proxy, in which case the last entry that
+// is not a trusted proxy, or the first one when they all are.
+func (r *settings) clientHop(header string) string {
+	if len(r.trustedProxies) == 0 || header == "" {
+		return lastHop(header)
+
+	}
+	hops := strings.Split(header, ",")
+	for i := len(hops) - 1; i > 0; i-- {
+		if !r.isTrustedProxy(strings.TrimSpace(hops[i])) {
+			return strings.TrimSpace(hops[i])
+
+		}
+
+	}
+	return strings.TrimSpace(hops[0])
+
+}
+
+// isTrustedProxy reports whether addr is one of the WithTrustedProxies.
+func (r *settings) isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(stripPort(addr))
+	if ip == nil {
+		return false
+
+	}
+	for _, network := range r.trustedProxies {
+		if network.Contains(ip) {
+			return true
+
+		}
+
+	}
+	return false
+
+}
+
+// lastHop returns the last entry of a comma separated proxy header, the only
+// one that was not forwarded from the client as-is.
+func lastHop(header string) string {
+	if header == "" {
+		return ""
+
+	}
+	hops := strings.Split(header, ",")
+	return strings.TrimSpace(hops[len(hops)-1])
+
+}
+
+// stripPort removes the port and IPv6 brackets from an address.
+func stripPort(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestConnectionInfoForwarded(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		header    http.Header
+		wantIP    string
+		wantProto string
+		wantHost  string
+	}{
+		{
+			name: "forwarded added by a trusted proxy",
+			opts: []Option{WithForwardedHeaderParsing(), WithTrustedProxies("10.0.0.0/8")},
+			header: http.Header{
+				"X-Forwarded-For":   {"10.0.0.1"},
+				"X-Forwarded-Proto": {"http"},
+				"Forwarded":         {"for=192.0.2.60;proto=https;host=example.com"},
+			},
+			wantIP:    "192.0.2.60",
+			wantProto: "https",
+			wantHost:  "example.com",
+		},
+		{
+			name: "trusted hops are skipped from the right",
+			opts: []Option{WithForwardedHeaderParsing(), WithTrustedProxies("10.0.0.0/8")},
+			header: http.Header{
+				"X-Forwarded-For": {"10.0.0.1"},
+				"Forwarded":       {`for=198.51.100.7, for="[2001:db8::1]:4711";proto=https, for=10.0.0.2`},
+			},
+			wantIP:    "2001:db8::1",
+			wantProto: "https",
+			wantHost:  "example.org",
+		},
+		{
+			name: "forwarded sent by the client is ignored",
+			opts: []Option{WithForwardedHeaderParsing(), WithTrustedProxies("10.0.0.0/8")},
+			header: http.Header{
+				"X-Forwarded-For":   {"203.0.113.9"},
+				"X-Forwarded-Proto": {"https"},
+				"Forwarded":         {"for=192.0.2.60;proto=http;host=evil.example"},
+			},
+			wantIP:    "203.0.113.9",
+			wantProto: "https",
+			wantHost:  "example.org",
+		},
+		{
+			name: "forwarded is ignored without trusted proxies",
+			opts: []Option{WithForwardedHeaderParsing()},
+			header: http.Header{
+				"X-Forwarded-For": {"203.0.113.9"},
+				"Forwarded":       {"for=192.0.2.60"},
+			},
+			wantIP:   "203.0.113.9",
+			wantHost: "example.org",
+		},
+		{
+			name: "obfuscated identifiers fall back to X-Forwarded-For",
+			opts: []Option{WithForwardedHeaderParsing(), WithTrustedProxies("10.0.0.0/8")},
+			header: http.Header{
+				"X-Forwarded-For": {"203.0.113.9, 10.0.0.1"},
+				"Forwarded":       {"for=unknown;host=example.com"},
+			},
+			wantIP:   "203.0.113.9",
+			wantHost: "example.com",
+		},
+		{
+			name: "forwarded is not parsed by default",
+			opts: []Option{WithTrustedProxies("10.0.0.0/8")},
+			header: http.Header{
+				"X-Forwarded-For": {"10.0.0.1"},
+				"Forwarded":       {"for=192.0.2.60"},
+			},
+			wantIP:   "10.0.0.1",
+			wantHost: "example.org",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRequestAccessor(tt.opts...)
+			req, _ := http.NewRequest(http.MethodGet, "https://example.org/", nil)
+			req.Header = tt.header
+			if got := r.ClientIP(req); got != tt.wantIP {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.wantIP)
+
+			}
+			if got := r.ClientProto(req); got != tt.wantProto {
+				t.Errorf("ClientProto() = %q, want %q", got, tt.wantProto)
+
+			}
+			if got := r.ClientHost(req); got != tt.wantHost {
+				t.Errorf("ClientHost() = %q, want %q", got, tt.wantHost)
+
+			}
+
+		})
+
+	}
+
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// healthCheckUserAgent starts the User-Agent of the ALB health checks.
+const healthCheckUserAgent = "ELB-HealthChecker"
+
+// isHealthCheck reports whether req is a health check: a request to the path
+// set with WithHealthCheck or sent by the ALB health checker.
+func (r *settings) isHealthCheck(req event) bool {
+	if !r.healthCheck {
+		return false
+
+	}
+	if r.healthCheckPath != "" && strings.SplitN(req.Path, "?", 2)[0] == r.healthCheckPath {
+		return true
+
+	}
+	return strings.HasPrefix(req.header().Get("User-Agent"), healthCheckUserAgent)
+
+}
+
+// healthCheckResponse answers a health check with a 200, or a 503 when the
+// readiness func set with WithHealthCheck fails.
+func (r *settings) healthCheckResponse(ctx context.Context) events.ALBTargetGroupResponse {
+	if r.readiness != nil {
+		if err := r.readiness(ctx); err != nil {
+			r.logRequestError(http.MethodGet, r.healthCheckPath, http.StatusServiceUnavailable, err)
+			return statusResponse(http.StatusServiceUnavailable)
+
+		}
+
+	}
+	return statusResponse(http.StatusOK)
+
+}
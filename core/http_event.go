@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// HTTPEvent is the HTTP request carried by an event, whatever its provider.
+// Implement it to serve the events of other serverless platforms or of
+// custom gateways with HandleHTTPEvent.
+type HTTPEvent interface {
+	// Method returns the HTTP method of the request.
+	Method() string
+	// Path returns the path of the request, percent-encoded.
+	Path() string
+	// Header returns the headers of the request.
+	Header() http.Header
+	// Query returns the query string of the request, percent-encoded.
+	Query() string
+	// Body returns the body of the request, base64 encoded when
+	// IsBase64Encoded returns true.
+	Body() string
+	// IsBase64Encoded reports whether Body is base64 encoded.
+	IsBase64Encoded() bool
+}
+
+// AsHTTPEvent returns the HTTPEvent of any of the supported AWS events.
+func AsHTTPEvent[E Event](req E) HTTPEvent {
+	return httpEvent{ev: newEvent(req)}
+
+}
+
+// httpEvent implements HTTPEvent for the supported AWS events.
+type httpEvent struct {
+	ev event
+}
+
+func (e httpEvent) Method() string {
+	return e.ev.HTTPMethod
+
+}
+
+func (e httpEvent) Path() string {
+	return e.ev.ALBTargetGroupRequest.Path
+
+}
+
+func (e httpEvent) Header() http.Header {
+	return e.ev.header()
+
+}
+
+func (e httpEvent) Query() string {
+	return eventQueryString(e.ev, false)
+
+}
+
+func (e httpEvent) Body() string {
+	return e.ev.ALBTargetGroupRequest.Body
+
+}
+
+func (e httpEvent) IsBase64Encoded() bool {
+	return e.ev.ALBTargetGroupRequest.IsBase64Encoded
+
+}
+
+// fromHTTPEvent converts an HTTPEvent into an event.
+func fromHTTPEvent(req HTTPEvent) event {
+	if e, ok := req.(httpEvent); ok {
+		return e.ev
+
+	}
+
+	header := req.Header()
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		headers[name] = strings.Join(values, ",")
+
+	}
+	return event{
+		ALBTargetGroupRequest: events.ALBTargetGroupRequest{
+			HTTPMethod:        req.Method(),
+			Path:              req.Path(),
+			Headers:           headers,
+			MultiValueHeaders: header,
+			IsBase64Encoded:   req.IsBase64Encoded(),
+			Body:              req.Body(),
+		},
+		source:   sourceCustom,
+		rawQuery: req.Query(),
+		raw:      req,
+	}
+
+}
+
+// HandleHTTPEvent serves an HTTPEvent with h like Handle. The response is
+// returned in the ALB shape, which holds everything the handler wrote; convert
+// it into the response shape of the provider of req. Custom HTTPEvent
+// implementations are never taken for ALB events, so the ALB specific
+// options, such as WithALBMutualTLS, do not apply to them.
+func (r *settings) HandleHTTPEvent(ctx context.Context, req HTTPEvent, h http.Handler) (events.ALBTargetGroupResponse, error) {
+	return r.handle(ctx, fromHTTPEvent(req), h)
+
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// gatewayEvent is an HTTPEvent of a custom gateway.
+type gatewayEvent struct {
+	header http.Header
+}
+
+func (e gatewayEvent) Method() string {
+	return http.MethodGet
+
+}
+
+func (e gatewayEvent) Path() string {
+	return "/"
+
+}
+
+func (e gatewayEvent) Header() http.Header {
+	return e.header
+
+}
+
+func (e gatewayEvent) Query() string {
+	return ""
+
+}
+
+func (e gatewayEvent) Body() string {
+	return ""
+
+}
+
+func (e gatewayEvent) IsBase64Encoded() bool {
+	return false
+
+}
+
+func TestHTTPEventIsNotALB(t *testing.T) {
+	_, pemData := clientCertificate(t)
+	encoded := strings.NewReplacer(" ", "%20", "\n", "%0A").Replace(pemData)
+	hasTLS := false
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		hasTLS = req.TLS != nil
+		w.WriteHeader(http.StatusOK)
+
+	})
+	r := NewRequestAccessor(WithALBMutualTLS())
+
+	custom := gatewayEvent{header: http.Header{"X-Amzn-Mtls-Clientcert-Leaf": {encoded}}}
+	if _, err := r.HandleHTTPEvent(context.Background(), custom, h); err != nil {
+		t.Fatal(err)
+
+	}
+	if hasTLS {
+		t.Errorf("custom event: got a client certificate from the request headers")
+
+	}
+
+	alb := events.ALBTargetGroupRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/",
+		Headers:    map[string]string{"x-amzn-mtls-clientcert-leaf": encoded},
+	}
+	if _, err := r.HandleHTTPEvent(context.Background(), AsHTTPEvent(alb), h); err != nil {
+		t.Fatal(err)
+
+	}
+	if !hasTLS {
+		t.Errorf("ALB event through AsHTTPEvent: got no client certificate")
+
+	}
+
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// VPCLatticeRequest is the event VPC Lattice sends to Lambda targets with the
+// version 1.0 event structure. Its raw path includes the query string as sent
+// by the client.
+type VPCLatticeRequest struct {
+	Method                string            `json:"method"`
+	RawPath               string            `json:"raw_path"`
+	Headers               map[string]string `json:"headers"`
+	QueryStringParameters map[string]string `json:"query_string_parameters"`
+	Body                  string            `json:"body"`
+	IsBase64Encoded       bool              `json:"is_base64_encoded"`
+}
+
+// VPCLatticeRequestV2 is the event VPC Lattice sends to Lambda targets with
+// the version 2.0 event structure, where headers and query parameters are
+// multi-valued.
+type VPCLatticeRequestV2 struct {
+	Version               string                   `json:"version"`
+	Path                  string                   `json:"path"`
+	Method                string                   `json:"method"`
+	Headers               map[string][]string      `json:"headers"`
+	QueryStringParameters map[string][]string      `json:"queryStringParameters"`
+	Body                  string                   `json:"body"`
+	IsBase64Encoded       bool                     `json:"isBase64Encoded"`
+	RequestContext        VPCLatticeRequestContext `json:"requestContext"`
+}
+
+// VPCLatticeRequestContext describes the service and the caller of a
+// VPCLatticeRequestV2.
+type VPCLatticeRequestContext struct {
+	ServiceNetworkARN string             `json:"serviceNetworkArn"`
+	ServiceARN        string             `json:"serviceArn"`
+	TargetGroupARN    string             `json:"targetGroupArn"`
+	Identity          VPCLatticeIdentity `json:"identity"`
+	Region            string             `json:"region"`
+	TimeEpoch         string             `json:"timeEpoch"`
+}
+
+// VPCLatticeIdentity describes the caller of a VPCLatticeRequestV2.
+type VPCLatticeIdentity struct {
+	SourceVPCARN   string `json:"sourceVpcArn"`
+	Type           string `json:"type"`
+	Principal      string `json:"principal"`
+	PrincipalOrgID string `json:"principalOrgID"`
+	SessionName    string `json:"sessionName"`
+	X509SanDNS     string `json:"x509SanDns"`
+	X509SanNameCN  string `json:"x509SanNameCn"`
+	X509SubjectCN  string `json:"x509SubjectCn"`
+	X509IssuerOu   string `json:"x509IssuerOu"`
+	X509SanURI     string `json:"x509SanUri"`
+}
+
+// VPCLatticeResponse is the response of a VPC Lattice Lambda target, for both
+// event structure versions.
+type VPCLatticeResponse struct {
+	StatusCode        int               `json:"statusCode"`
+	StatusDescription string            `json:"statusDescription,omitempty"`
+	Headers           map[string]string `json:"headers"`
+	Body              string            `json:"body"`
+	IsBase64Encoded   bool              `json:"isBase64Encoded"`
+}
+
+// latticeEvent converts a version 1.0 VPC Lattice event. The query string is
+// taken from the raw path, already encoded, rather than from the decoded
+// parameters.
+func latticeEvent(req VPCLatticeRequest) event {
+	path, rawQuery, _ := strings.Cut(req.RawPath, "?")
+	return event{
+		ALBTargetGroupRequest: events.ALBTargetGroupRequest{
+			HTTPMethod:            req.Method,
+			Path:                  path,
+			QueryStringParameters: req.QueryStringParameters,
+			Headers:               req.Headers,
+			IsBase64Encoded:       req.IsBase64Encoded,
+			Body:                  req.Body,
+		},
+		rawQuery: rawQuery,
+	}
+
+}
+
+// latticeEventV2 converts a version 2.0 VPC Lattice event.
+func latticeEventV2(req VPCLatticeRequestV2) event {
+	headers := make(map[string]string, len(req.Headers))
+	for name, values := range req.Headers {
+		headers[name] = strings.Join(values, ",")
+
+	}
+	path, rawQuery, _ := strings.Cut(req.Path, "?")
+	requestContext := req.RequestContext
+	return event{
+		ALBTargetGroupRequest: events.ALBTargetGroupRequest{
+			HTTPMethod:                      req.Method,
+			Path:                            path,
+			MultiValueQueryStringParameters: req.QueryStringParameters,
+			Headers:                         headers,
+			MultiValueHeaders:               req.Headers,
+			IsBase64Encoded:                 req.IsBase64Encoded,
+			Body:                            req.Body,
+		},
+		rawQuery:       rawQuery,
+		latticeContext: &requestContext,
+	}
+
+}
+
+// ALBToVPCLatticeResponse converts an ALB response into a VPC Lattice
+// response. VPC Lattice has no multi-value headers: values are joined with a
+// comma.
+func ALBToVPCLatticeResponse(resp events.ALBTargetGroupResponse) VPCLatticeResponse {
+	headers := make(map[string]string, len(resp.Headers)+len(resp.MultiValueHeaders))
+	for name, value := range resp.Headers {
+		headers[name] = value
+
+	}
+	for name, values := range resp.MultiValueHeaders {
+		headers[name] = strings.Join(values, ",")
+
+	}
+	return VPCLatticeResponse{
+		StatusCode:        resp.StatusCode,
+		StatusDescription: description(resp.StatusCode) + " " + http.StatusText(resp.StatusCode),
+		Headers:           headers,
+		Body:              resp.Body,
+		IsBase64Encoded:   resp.IsBase64Encoded,
+	}
+
+}
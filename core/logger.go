@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+)
+
+// Logger is the interface used by the library to log. *log.Logger satisfies
+// it. Use WithLogger to replace the default standard logger, or WithSlog to
+// log structured records with a *slog.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// slogLogger adapts a *slog.Logger to the Logger interface.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// Printf logs the formatted message as an info record.
+func (s slogLogger) Printf(format string, v ...interface{}) {
+	s.logger.Info(fmt.Sprintf(format, v...))
+
+}
+
+// log returns the configured Logger, defaulting to the standard logger.
+func (r *settings) log() Logger {
+	if r.logger == nil {
+		return log.Default()
+
+	}
+	return r.logger
+
+}
+
+// logRequestError logs err for the request identified by method and path.
+// A status of 0 means no response was produced. With a slog logger the
+// known fields are logged as attributes instead of being formatted.
+func (r *settings) logRequestError(method string, path string, status int, err error) {
+	if s, ok := r.logger.(slogLogger); ok {
+		attrs := []slog.Attr{slog.String("method", method), slog.String("path", path)}
+		if status != 0 {
+			attrs = append(attrs, slog.Int("status", status))
+
+		}
+		attrs = append(attrs, slog.Any("error", err))
+		s.logger.LogAttrs(context.Background(), slog.LevelError, "request failed", attrs...)
+		return
+
+	}
+
+	if status != 0 {
+		r.log().Printf("%s %s %d: %v", method, path, status, err)
+		return
+
+	}
+	r.log().Printf("%s %s: %v", method, path, err)
+
+}
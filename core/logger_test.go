@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// recordHandler is a slog.Handler keeping the records it handles.
+type recordHandler struct {
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+
+}
+
+func (h *recordHandler) Handle(_ context.Context, record slog.Record) error {
+	h.records = append(h.records, record)
+	return nil
+
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return h
+
+}
+
+func (h *recordHandler) WithGroup(string) slog.Handler {
+	return h
+
+}
+
+func TestSlogRequestErrorAttributes(t *testing.T) {
+	records := &recordHandler{}
+	r := NewRequestAccessor(WithSlog(slog.New(records)), WithPanicRecovery())
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic("boom")
+
+	})
+	req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/items"}
+	r.Handle(context.Background(), req, h)
+
+	if len(records.records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records.records))
+
+	}
+	record := records.records[0]
+	if record.Level != slog.LevelError || record.Message != "request failed" {
+		t.Errorf("got %v %q, want ERROR \"request failed\"", record.Level, record.Message)
+
+	}
+	attrs := map[string]slog.Value{}
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value
+		return true
+
+	})
+	if attrs["method"].String() != http.MethodGet || attrs["path"].String() != "/items" || attrs["status"].Int64() != http.StatusInternalServerError {
+		t.Errorf("got attributes %v, want method GET, path /items and status 500", attrs)
+
+	}
+	if err, ok := attrs["error"].Any().(*PanicError); !ok || err.Value != "boom" {
+		t.Errorf("got error attribute %v, want the *PanicError of boom", attrs["error"])
+
+	}
+
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the latency histogram.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metrics accumulates the counters exposed by RequestAccessor.MetricsHandler
+// for the lifetime of the Lambda container.
+type metrics struct {
+	mu sync.Mutex
+
+	requests      map[int]uint64
+	errors        uint64
+	requestBytes  int64
+	responseBytes int64
+
+	latencyCounts []uint64
+	latencySum    float64
+	latencyCount  uint64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:      make(map[int]uint64),
+		latencyCounts: make([]uint64, len(latencyBuckets)),
+	}
+
+}
+
+// observe records a served request.
+func (m *metrics) observe(status int, elapsed time.Duration, requestBytes int64, responseBytes int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[status]++
+	if status >= http.StatusInternalServerError {
+		m.errors++
+
+	}
+	if requestBytes > 0 {
+		m.requestBytes += requestBytes
+
+	}
+	m.responseBytes += int64(responseBytes)
+
+	seconds := elapsed.Seconds()
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			m.latencyCounts[i]++
+
+		}
+
+	}
+	m.latencySum += seconds
+	m.latencyCount++
+
+}
+
+// observe records a request served since start when WithMetrics is set.
+func (r *settings) observe(status int, start time.Time, requestBytes int64, responseBytes int) {
+	if r.metrics != nil {
+		r.metrics.observe(status, time.Since(start), requestBytes, responseBytes)
+
+	}
+
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *metrics) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.Header().Set(contentTypeHeaderKey, "text/plain; version=0.0.4; charset=utf-8")
+
+	fmt.Fprintln(w, "# HELP lambda_http_requests_total Requests served, by status code.")
+	fmt.Fprintln(w, "# TYPE lambda_http_requests_total counter")
+	codes := make([]int, 0, len(m.requests))
+	for code := range m.requests {
+		codes = append(codes, code)
+
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "lambda_http_requests_total{code=\"%d\"} %d\n", code, m.requests[code])
+
+	}
+
+	fmt.Fprintln(w, "# HELP lambda_http_errors_total Requests answered with a 5xx status code.")
+	fmt.Fprintln(w, "# TYPE lambda_http_errors_total counter")
+	fmt.Fprintf(w, "lambda_http_errors_total %d\n", m.errors)
+
+	fmt.Fprintln(w, "# HELP lambda_http_request_duration_seconds Time spent serving requests.")
+	fmt.Fprintln(w, "# TYPE lambda_http_request_duration_seconds histogram")
+	for i, bound := range latencyBuckets {
+		fmt.Fprintf(w, "lambda_http_request_duration_seconds_bucket{le=\"%s\"} %d\n", strconv.FormatFloat(bound, 'g', -1, 64), m.latencyCounts[i])
+
+	}
+	fmt.Fprintf(w, "lambda_http_request_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.latencyCount)
+	fmt.Fprintf(w, "lambda_http_request_duration_seconds_sum %s\n", strconv.FormatFloat(m.latencySum, 'g', -1, 64))
+	fmt.Fprintf(w, "lambda_http_request_duration_seconds_count %d\n", m.latencyCount)
+
+	fmt.Fprintln(w, "# HELP lambda_http_request_bytes_total Bytes received in request bodies.")
+	fmt.Fprintln(w, "# TYPE lambda_http_request_bytes_total counter")
+	fmt.Fprintf(w, "lambda_http_request_bytes_total %d\n", m.requestBytes)
+
+	fmt.Fprintln(w, "# HELP lambda_http_response_bytes_total Bytes sent in response bodies.")
+	fmt.Fprintln(w, "# TYPE lambda_http_response_bytes_total counter")
+	fmt.Fprintf(w, "lambda_http_response_bytes_total %d\n", m.responseBytes)
+
+}
+
+// MetricsHandler returns an http.Handler exposing the request, error, latency
+// and byte counters collected since the container started in the Prometheus
+// text format. Mount it on a dedicated route or serve it from a separate
+// export invocation. Metrics are only collected when WithMetrics is set;
+// otherwise the handler answers 404.
+func (r *settings) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if r.metrics == nil {
+			http.NotFound(w, req)
+			return
+
+		}
+		r.metrics.ServeHTTP(w, req)
+
+	})
+
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestMetricsObserveEveryResponse(t *testing.T) {
+	maintenance := false
+	r := NewRequestAccessor(
+		WithMetrics(),
+		WithMaxRequestBodySize(2),
+		WithMaintenanceMode(func() bool { return maintenance }, events.ALBTargetGroupResponse{}),
+	)
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/created" {
+			w.WriteHeader(http.StatusCreated)
+
+		}
+		io.WriteString(w, "ok")
+
+	})
+	get := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/"}
+
+	if resp, _ := r.Handle(context.Background(), get, h); resp.StatusCode != http.StatusOK {
+		t.Fatalf("served: got %d, want 200", resp.StatusCode)
+
+	}
+	tooLarge := events.ALBTargetGroupRequest{HTTPMethod: http.MethodPost, Path: "/", Body: "hello"}
+	if resp, _ := r.Handle(context.Background(), tooLarge, h); resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("too large: got %d, want 413", resp.StatusCode)
+
+	}
+	maintenance = true
+	if resp, _ := r.Handle(context.Background(), get, h); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("maintenance: got %d, want 503", resp.StatusCode)
+
+	}
+	maintenance = false
+	created := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/created"}
+	streamed, err := r.Stream(context.Background(), created, h)
+	if err != nil || streamed.StatusCode != http.StatusCreated {
+		t.Fatalf("streamed: got %v, want 201", err)
+
+	}
+	io.ReadAll(streamed.Body)
+
+	rec := httptest.NewRecorder()
+	r.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	exposition := rec.Body.String()
+	for _, line := range []string{
+		`lambda_http_requests_total{code="200"} 1`,
+		`lambda_http_requests_total{code="201"} 1`,
+		`lambda_http_requests_total{code="413"} 1`,
+		`lambda_http_requests_total{code="503"} 1`,
+		`lambda_http_errors_total 1`,
+		`lambda_http_request_duration_seconds_count 4`,
+		`lambda_http_request_bytes_total 5`,
+	} {
+		if !strings.Contains(exposition, line+"\n") {
+			t.Errorf("exposition lacks %q:\n%s", line, exposition)
+
+		}
+
+	}
+
+}
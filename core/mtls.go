@@ -0,0 +1,87 @@
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+)
+
+// ALB mutual TLS adds the URL-encoded PEM client certificate to the requests
+// in these headers, the leaf one in verify mode and the chain in passthrough mode.
+// Clients can send them too to load balancers without mutual TLS, so they are
+// only trusted with WithALBMutualTLS.
+const (
+	mtlsLeafHeader  = "X-Amzn-Mtls-Clientcert-Leaf"
+	mtlsChainHeader = "X-Amzn-Mtls-Clientcert"
+)
+
+// clientCertPEM returns the PEM encoded client certificate of a request made
+// with mutual TLS, or an empty string. The certificate of ALB requests comes
+// from headers and is ignored unless WithALBMutualTLS is set.
+func (r *settings) clientCertPEM(req event, header http.Header) string {
+	switch {
+	case req.apiGatewayContext != nil && req.apiGatewayContext.Identity.ClientCert != nil:
+		return req.apiGatewayContext.Identity.ClientCert.ClientCertPem
+
+	case req.httpAPIContext != nil:
+		return req.httpAPIContext.Authentication.ClientCert.ClientCertPem
+
+	case r.albMutualTLS && req.isALB():
+		encoded := header.Get(mtlsLeafHeader)
+		if encoded == "" {
+			encoded = header.Get(mtlsChainHeader)
+
+		}
+		// ALB leaves the +, / and = of the base64 body unescaped, so + is no space.
+		decoded, err := url.PathUnescape(encoded)
+		if err != nil {
+			return ""
+
+		}
+		return decoded
+
+	}
+	return ""
+
+}
+
+// setPeerCertificates exposes the client certificates of a request made with
+// mutual TLS in req.TLS.PeerCertificates, like net/http servers requesting
+// client certificates do. Certificates that cannot be parsed are skipped.
+func setPeerCertificates(req *http.Request, pemData string) {
+	var certificates []*x509.Certificate
+	rest := []byte(pemData)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+
+		}
+		if certificate, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certificates = append(certificates, certificate)
+
+		}
+
+	}
+	if len(certificates) == 0 {
+		return
+
+	}
+
+	if req.TLS == nil {
+		req.TLS = &tls.ConnectionState{
+			HandshakeComplete: true,
+			ServerName:        stripPort(req.Host),
+		}
+
+	}
+	req.TLS.PeerCertificates = certificates
+
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// clientCertificate returns a self-signed certificate whose PEM form contains
+// a +, which ALB leaves unescaped in the mutual TLS headers.
+func clientCertificate(t *testing.T) (*x509.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+
+	}
+	for serial := int64(1); serial < 1000; serial++ {
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "client.example.org"},
+			NotBefore:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			NotAfter:     time.Date(2034, 1, 1, 0, 0, 0, 0, time.UTC),
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		if err != nil {
+			t.Fatal(err)
+
+		}
+		pemData := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+		if strings.Contains(pemData, "+") {
+			return template, pemData
+
+		}
+
+	}
+	t.Fatal("could not generate a certificate containing a +")
+	return nil, ""
+
+}
+
+func TestALBMutualTLS(t *testing.T) {
+	template, pemData := clientCertificate(t)
+	// ALB escapes the spaces and line breaks of the PEM but not +, / and =.
+	encoded := strings.NewReplacer(" ", "%20", "\n", "%0A").Replace(pemData)
+
+	var certificates []*x509.Certificate
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		certificates = nil
+		if req.TLS != nil {
+			certificates = req.TLS.PeerCertificates
+
+		}
+		w.WriteHeader(http.StatusOK)
+
+	})
+	req := events.ALBTargetGroupRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/",
+		Headers:    map[string]string{"x-amzn-mtls-clientcert-leaf": encoded},
+	}
+
+	if _, err := NewRequestAccessor(WithALBMutualTLS()).Handle(context.Background(), req, h); err != nil {
+		t.Fatal(err)
+
+	}
+	if len(certificates) != 1 {
+		t.Fatalf("got %d peer certificates, want 1", len(certificates))
+
+	}
+	got := certificates[0]
+	if got.Subject.CommonName != template.Subject.CommonName || !got.NotBefore.Equal(template.NotBefore) || !got.NotAfter.Equal(template.NotAfter) {
+		t.Errorf("got %s valid from %v to %v, want %s valid from %v to %v",
+			got.Subject, got.NotBefore, got.NotAfter, template.Subject, template.NotBefore, template.NotAfter)
+
+	}
+
+	if _, err := NewRequestAccessor().Handle(context.Background(), req, h); err != nil {
+		t.Fatal(err)
+
+	}
+	if len(certificates) != 0 {
+		t.Errorf("without WithALBMutualTLS: got %d peer certificates, want none", len(certificates))
+
+	}
+
+}
+
+func TestMutualTLSHeadersIgnoredOutsideALB(t *testing.T) {
+	_, pemData := clientCertificate(t)
+	encoded := strings.NewReplacer(" ", "%20", "\n", "%0A").Replace(pemData)
+	hasTLS := false
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		hasTLS = req.TLS != nil
+		w.WriteHeader(http.StatusOK)
+
+	})
+
+	r := NewAccessor[VPCLatticeRequest, VPCLatticeResponse](WithALBMutualTLS())
+	req := VPCLatticeRequest{
+		Method:  http.MethodGet,
+		RawPath: "/",
+		Headers: map[string]string{"x-amzn-mtls-clientcert-leaf": encoded},
+	}
+	if _, err := r.Handle(context.Background(), req, h); err != nil {
+		t.Fatal(err)
+
+	}
+	if hasTLS {
+		t.Errorf("VPC Lattice: got a client certificate from the request headers")
+
+	}
+
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"sync"
+)
+
+// DefaultMultipartMaxMemory is the part of a multipart form kept in memory by
+// ParseMultipartForm; larger files spill to os.TempDir(), /tmp on Lambda.
+const DefaultMultipartMaxMemory = 8 << 20
+
+// DefaultMultipartMaxSize is the largest multipart body ParseMultipartForm
+// accepts by default, well below the 512 MB of default ephemeral storage.
+const DefaultMultipartMaxSize = 256 << 20
+
+// MultipartLimits bounds the parsing of multipart forms. Zero values use the
+// defaults.
+type MultipartLimits struct {
+	// MaxMemory is the number of bytes of file parts kept in memory.
+	MaxMemory int64
+	// MaxSize is the largest accepted request body.
+	MaxSize int64
+}
+
+// multipartForms are the forms parsed while serving a request, whose
+// temporary files are removed once the request is served.
+type multipartForms struct {
+	mu    sync.Mutex
+	forms []*multipart.Form
+}
+
+// ParseMultipartForm parses the multipart body of req into req.MultipartForm
+// within limits. It returns a 413 *HTTPError for bodies larger than MaxSize
+// and a 400 one for malformed bodies. The files spilled to disk are removed
+// when the invocation is served, even when req is a copy of the request
+// created by the Accessor, so they do not fill the ephemeral storage shared
+// by the invocations of an execution environment.
+func ParseMultipartForm(req *http.Request, limits MultipartLimits) error {
+	if limits.MaxMemory <= 0 {
+		limits.MaxMemory = DefaultMultipartMaxMemory
+
+	}
+	if limits.MaxSize <= 0 {
+		limits.MaxSize = DefaultMultipartMaxSize
+
+	}
+	if req.ContentLength > limits.MaxSize {
+		return &HTTPError{StatusCode: http.StatusRequestEntityTooLarge, Message: "request body too large"}
+
+	}
+
+	req.Body = http.MaxBytesReader(nil, req.Body, limits.MaxSize)
+	err := req.ParseMultipartForm(limits.MaxMemory)
+	if req.MultipartForm != nil {
+		if forms, ok := FromContext[*multipartForms](req.Context()); ok {
+			forms.mu.Lock()
+			forms.forms = append(forms.forms, req.MultipartForm)
+			forms.mu.Unlock()
+
+		}
+
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return &HTTPError{StatusCode: http.StatusRequestEntityTooLarge, Message: "request body too large"}
+
+	}
+	if err != nil {
+		return badRequest("invalid multipart form: " + err.Error())
+
+	}
+	return nil
+
+}
+
+// removeMultipartForms removes the temporary files of the multipart forms
+// parsed while serving req, like net/http servers do once a handler returns.
+func removeMultipartForms(req *http.Request) {
+	if req.MultipartForm != nil {
+		req.MultipartForm.RemoveAll()
+
+	}
+	forms, ok := FromContext[*multipartForms](req.Context())
+	if !ok {
+		return
+
+	}
+	forms.mu.Lock()
+	defer forms.mu.Unlock()
+	for _, form := range forms.forms {
+		form.RemoveAll()
+
+	}
+	forms.forms = nil
+
+}
@@ -0,0 +1,296 @@
+package core
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ALB authenticate actions add these headers to the requests of signed in users.
+const (
+	oidcDataHeader        = "X-Amzn-Oidc-Data"
+	oidcIdentityHeader    = "X-Amzn-Oidc-Identity"
+	oidcAccessTokenHeader = "X-Amzn-Oidc-Accesstoken"
+)
+
+// albPublicKeyURL is where ALB publishes the public key of each key ID of a region.
+const albPublicKeyURL = "https://public-keys.auth.elb.%s.amazonaws.com/%s"
+
+// Principal is the user an ALB authenticate action signed in, decoded from the
+// verified x-amzn-oidc-data token.
+type Principal struct {
+	// Subject is the sub claim, the user ID at the identity provider.
+	Subject string
+	// Identity is the x-amzn-oidc-identity header.
+	Identity string
+	// AccessToken is the x-amzn-oidc-accesstoken header, the access token
+	// received from the identity provider.
+	AccessToken string
+	// Claims holds the user claims of the token.
+	Claims    map[string]interface{}
+	ExpiresAt time.Time
+}
+
+// GetPrincipalFromContext retrieve the user signed in by the ALB authenticate action from context.Context.
+// Returns false unless WithALBOIDC is set and the request carried a valid token.
+func GetPrincipalFromContext(ctx context.Context) (Principal, bool) {
+	return FromContext[Principal](ctx)
+
+}
+
+// oidcHeader is the header of the token signed by ALB.
+type oidcHeader struct {
+	Alg    string `json:"alg"`
+	Kid    string `json:"kid"`
+	Signer string `json:"signer"`
+}
+
+// albPrincipal verifies the ALB OIDC token of a request and decodes its user.
+// It returns nil when WithALBOIDC is not set or the request has no token, and
+// a 401 *HTTPError when the token is invalid.
+func (r *settings) albPrincipal(ctx context.Context, header http.Header) (*Principal, error) {
+	token := header.Get(oidcDataHeader)
+	if len(r.oidcSigners) == 0 || token == "" {
+		return nil, nil
+
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, unauthorized("malformed OIDC token")
+
+	}
+	var jwtHeader oidcHeader
+	if err := decodeSegment(parts[0], &jwtHeader); err != nil {
+		return nil, unauthorized("malformed OIDC token header")
+
+	}
+	if jwtHeader.Alg != "ES256" {
+		return nil, unauthorized("unexpected OIDC token algorithm " + jwtHeader.Alg)
+
+	}
+	if !r.isOIDCSigner(jwtHeader.Signer) {
+		return nil, unauthorized("OIDC token signed by an unexpected load balancer")
+
+	}
+
+	region := arnRegion(jwtHeader.Signer)
+	if region == "" || jwtHeader.Kid == "" {
+		return nil, unauthorized("OIDC token without signer or key ID")
+
+	}
+	key, err := albPublicKeys.get(ctx, region, jwtHeader.Kid)
+	if err != nil {
+		r.log().Printf("could not fetch ALB public key %s: %v", jwtHeader.Kid, err)
+		return nil, &HTTPError{StatusCode: http.StatusServiceUnavailable, Message: "could not verify OIDC token"}
+
+	}
+	signature, err := decodeBase64URL(parts[2])
+	if err != nil || len(signature) != 64 {
+		return nil, unauthorized("malformed OIDC token signature")
+
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	rs, ss := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(key, digest[:], rs, ss) {
+		return nil, unauthorized("invalid OIDC token signature")
+
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, unauthorized("malformed OIDC token claims")
+
+	}
+	principal := &Principal{
+		Identity:    header.Get(oidcIdentityHeader),
+		AccessToken: header.Get(oidcAccessTokenHeader),
+		Claims:      claims,
+	}
+	principal.Subject, _ = claims["sub"].(string)
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, unauthorized("OIDC token without expiration")
+
+	}
+	principal.ExpiresAt = time.Unix(int64(exp), 0)
+	if time.Now().After(principal.ExpiresAt) {
+		return nil, unauthorized("expired OIDC token")
+
+	}
+	return principal, nil
+
+}
+
+// isOIDCSigner reports whether signer is one of the load balancers passed to WithALBOIDC.
+func (r *settings) isOIDCSigner(signer string) bool {
+	for _, s := range r.oidcSigners {
+		if s == signer {
+			return true
+
+		}
+
+	}
+	return false
+
+}
+
+// unauthorized returns an *HTTPError with the 401 status code.
+func unauthorized(message string) *HTTPError {
+	return &HTTPError{StatusCode: http.StatusUnauthorized, Message: message}
+
+}
+
+// arnRegion returns the region of an ARN such as
+// arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/name/id.
+func arnRegion(arn string) string {
+	fields := strings.SplitN(arn, ":", 5)
+	if len(fields) < 5 {
+		return ""
+
+	}
+	return fields[3]
+
+}
+
+// decodeSegment decodes a base64url encoded JSON token segment into v.
+func decodeSegment(segment string, v interface{}) error {
+	data, err := decodeBase64URL(segment)
+	if err != nil {
+		return err
+
+	}
+	return json.Unmarshal(data, v)
+
+}
+
+// decodeBase64URL decodes base64url with or without padding, since ALB pads
+// the segments of its tokens.
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+
+}
+
+// Since the key ID comes from the token, fetches of unknown keys are bounded:
+// a failed fetch is not retried for publicKeyRetryInterval, and at most one
+// key is fetched per publicKeyFetchInterval.
+const (
+	publicKeyRetryInterval = time.Minute
+	publicKeyFetchInterval = time.Second
+)
+
+// publicKeyCache keeps the ALB public keys for the lifetime of the execution
+// environment, so that only the first invocation using a key fetches it.
+type publicKeyCache struct {
+	mu        sync.Mutex
+	keys      map[string]*ecdsa.PublicKey
+	failures  map[string]time.Time
+	lastFetch time.Time
+	client    *http.Client
+}
+
+var albPublicKeys = &publicKeyCache{
+	keys:     make(map[string]*ecdsa.PublicKey),
+	failures: make(map[string]time.Time),
+	client:   &http.Client{Timeout: 5 * time.Second},
+}
+
+// get returns the public key kid of region, fetching it on first use.
+func (c *publicKeyCache) get(ctx context.Context, region string, kid string) (*ecdsa.PublicKey, error) {
+	keyURL := fmt.Sprintf(albPublicKeyURL, region, url.PathEscape(kid))
+
+	c.mu.Lock()
+	key, ok := c.keys[keyURL]
+	if ok {
+		c.mu.Unlock()
+		return key, nil
+
+	}
+	now := time.Now()
+	if failed, ok := c.failures[keyURL]; ok && now.Sub(failed) < publicKeyRetryInterval {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("previous fetch failed at %s", failed.Format(time.RFC3339))
+
+	}
+	if now.Sub(c.lastFetch) < publicKeyFetchInterval {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("too many key fetches")
+
+	}
+	c.lastFetch = now
+	c.mu.Unlock()
+
+	key, err := c.fetch(ctx, keyURL)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		for u, failed := range c.failures {
+			if now.Sub(failed) >= publicKeyRetryInterval {
+				delete(c.failures, u)
+
+			}
+
+		}
+		c.failures[keyURL] = now
+		return nil, err
+
+	}
+	delete(c.failures, keyURL)
+	c.keys[keyURL] = key
+	return key, nil
+
+}
+
+// fetch downloads and parses the PEM encoded public key at keyURL.
+func (c *publicKeyCache) fetch(ctx context.Context, keyURL string) (*ecdsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, keyURL, nil)
+	if err != nil {
+		return nil, err
+
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, err
+
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data")
+
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+
+	}
+	key, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unexpected key type %T", pub)
+
+	}
+	return key, nil
+
+}
@@ -0,0 +1,644 @@
+package core
+
+import (
+	"context"
+	"crypto/md5"
+	"hash"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Option configures an Accessor, whatever its event type. Options are usually passed to the
+// New function of a framework adapter, or applied directly with Configure.
+type Option func(*settings)
+
+// Configure applies the given options to the Accessor.
+func (r *settings) Configure(opts ...Option) {
+	for _, opt := range opts {
+		opt(r)
+
+	}
+
+}
+
+// WithServerAddress sets the scheme and host of converted requests, such as
+// https://api.example.com, taking precedence over the GO_API_HOST variable.
+func WithServerAddress(address string) Option {
+	return func(r *settings) {
+		r.serverAddress = address
+
+	}
+
+}
+
+// WithStripBasePath removes basePath from the path of converted requests, as
+// StripBasePath does.
+func WithStripBasePath(basePath string) Option {
+	return func(r *settings) {
+		r.StripBasePath(basePath)
+
+	}
+
+}
+
+// WithStripBasePaths removes the longest of basePaths matching the path of
+// converted requests, as StripBasePaths does.
+func WithStripBasePaths(basePaths ...string) Option {
+	return func(r *settings) {
+		r.StripBasePaths(basePaths)
+
+	}
+
+}
+
+// WithHostBasePaths strips a different base path depending on the Host
+// header of the request, for functions serving several custom domains, such
+// as {"api.example.com": "/api", "internal.example.com": ""}. An empty base
+// path strips nothing; hosts missing from the map use the StripBasePaths.
+func WithHostBasePaths(basePaths map[string]string) Option {
+	return func(r *settings) {
+		r.hostBasePaths = make(map[string]string, len(basePaths))
+		for host, basePath := range basePaths {
+			r.hostBasePaths[strings.ToLower(host)] = normalizeBasePath(basePath)
+
+		}
+
+	}
+
+}
+
+// WithRewriteRules rewrites the path of converted requests with the first
+// matching rule, so that legacy URL layouts reach the routes of the
+// application unchanged. The whole path is replaced by the expanded
+// replacement of the rule; RequestURI keeps the path as received.
+func WithRewriteRules(rules ...RewriteRule) Option {
+	return func(r *settings) {
+		r.rewriteRules = append(r.rewriteRules, rules...)
+
+	}
+
+}
+
+// WithTrustedProxies declares the proxies, as IP addresses or CIDR ranges,
+// that sit between the load balancer and the clients, such as a CDN. ClientIP
+// then skips their addresses from the end of X-Forwarded-For and returns the
+// first untrusted one. It panics on an invalid address or range.
+func WithTrustedProxies(proxies ...string) Option {
+	return func(r *settings) {
+		for _, proxy := range proxies {
+			if !strings.Contains(proxy, "/") {
+				if strings.Contains(proxy, ":") {
+					proxy += "/128"
+
+				} else {
+					proxy += "/32"
+
+				}
+
+			}
+			_, network, err := net.ParseCIDR(proxy)
+			if err != nil {
+				panic("core: invalid trusted proxy " + proxy)
+
+			}
+			r.trustedProxies = append(r.trustedProxies, network)
+
+		}
+
+	}
+
+}
+
+// WithRequestDecompression decompresses gzip and deflate request bodies
+// before they reach the handler and removes their Content-Encoding header,
+// since most routers do not expect compressed bodies. The decompressed size is
+// capped by WithMaxDecompressionRatio and WithMaxDecompressedBodySize; larger
+// bodies are answered with a 413 and corrupt ones with a 400.
+func WithRequestDecompression() Option {
+	return func(r *settings) {
+		r.decompressRequests = true
+
+	}
+
+}
+
+// WithBodyChecksum verifies the body of requests carrying the given header
+// against the checksum computed with newHash, such as sha256.New, and answers
+// those that do not match with a 400. The header holds the checksum, base64 or
+// hex encoded, of the body as sent.
+func WithBodyChecksum(header string, newHash func() hash.Hash) Option {
+	return func(r *settings) {
+		r.checksumHeader = header
+		r.checksumHash = newHash
+
+	}
+
+}
+
+// WithContentMD5 verifies the Content-MD5 header of requests, like
+// WithBodyChecksum("Content-MD5", md5.New).
+func WithContentMD5() Option {
+	return WithBodyChecksum("Content-MD5", md5.New)
+
+}
+
+// WithMaxDecompressionRatio limits how much larger than the compressed
+// request body the decompressed body may grow. Decompression aborts with
+// ErrDecompressionLimit once the output exceeds compressedSize * n.
+// Defaults to DefaultMaxDecompressionRatio.
+func WithMaxDecompressionRatio(n int) Option {
+	return func(r *settings) {
+		r.maxDecompressionRatio = n
+
+	}
+
+}
+
+// WithMaxDecompressedBodySize sets an absolute cap, in bytes, on the size of
+// a decompressed request body regardless of the ratio.
+// Defaults to DefaultMaxDecompressedBodySize.
+func WithMaxDecompressedBodySize(n int64) Option {
+	return func(r *settings) {
+		r.maxDecompressedBodySize = n
+
+	}
+
+}
+
+// WithMaxRequestBodySize answers requests whose body is larger than n bytes
+// once decoded with a 413 response, before the body is decoded or buffered.
+func WithMaxRequestBodySize(n int64) Option {
+	return func(r *settings) {
+		r.maxRequestBodySize = n
+
+	}
+
+}
+
+// WithPrettyJSON re-indents application/json response bodies so they are
+// readable in logs and local emulators. It changes the response body and is
+// meant for debugging only; never enable it in production.
+func WithPrettyJSON() Option {
+	return func(r *settings) {
+		r.prettyJSON = true
+
+	}
+
+}
+
+// WithDownstreamClient sets the client returned, bounded by the invocation
+// deadline, by GetHTTPClientFromContext. Defaults to http.DefaultClient.
+func WithDownstreamClient(base *http.Client) Option {
+	return func(r *settings) {
+		r.downstreamClient = base
+
+	}
+
+}
+
+// WithAccessLog calls log with an AccessLogEntry once each request has been
+// served and converted into a response.
+func WithAccessLog(log func(AccessLogEntry)) Option {
+	return func(r *settings) {
+		r.accessLog = log
+
+	}
+
+}
+
+// WithRedactedQueryParams replaces the values of the named query parameters
+// with *** in everything the library logs. Names are matched case
+// insensitively. The request passed to the handler is left untouched.
+func WithRedactedQueryParams(names []string) Option {
+	return func(r *settings) {
+		r.redactedQueryParams = names
+
+	}
+
+}
+
+// WithDecodedPathRouting guarantees that URL.Path holds the fully decoded
+// request path, so routers matching on URL.Path, like gorilla/mux, see
+// /files/a%2Fb as /files/a/b, and that URL.RawPath always holds the path
+// exactly as received, even when it is the default encoding of URL.Path.
+func WithDecodedPathRouting() Option {
+	return func(r *settings) {
+		r.decodedPathRouting = true
+
+	}
+
+}
+
+// PathNormalization selects how WithPathNormalization handles request paths
+// with duplicate or trailing slashes.
+type PathNormalization int
+
+const (
+	// NormalizeRewrite serves /a//b/ as /a/b.
+	NormalizeRewrite PathNormalization = iota + 1
+	// NormalizeRedirect answers /a//b/ with a permanent redirect to /a/b.
+	NormalizeRedirect
+)
+
+// WithPathNormalization collapses duplicate slashes and removes trailing
+// slashes from request paths, which ALB forwards verbatim and many routers do
+// not match, either by rewriting the path or redirecting the client.
+func WithPathNormalization(mode PathNormalization) Option {
+	return func(r *settings) {
+		r.pathNormalization = mode
+
+	}
+
+}
+
+// WithRawQueryString passes the query parameters of ALB events through to
+// URL.RawQuery as received instead of escaping them again, since ALB does not
+// decode them: a%2Bb stays a%2Bb rather than becoming a%252Bb. The raw query
+// string of API Gateway HTTP API and Function URL events is always kept.
+func WithRawQueryString() Option {
+	return func(r *settings) {
+		r.rawQueryString = true
+
+	}
+
+}
+
+// WithALBOIDC verifies the x-amzn-oidc-data token that ALB authenticate
+// actions add to the requests of signed in users and exposes the user with
+// GetPrincipalFromContext. albARNs are the load balancers allowed to sign the
+// token; requests with an invalid or expired token, or one without expiration,
+// are answered with 401. The public keys of ALB are fetched on first use and
+// cached across invocations; requests whose key cannot be fetched get a 503.
+func WithALBOIDC(albARNs ...string) Option {
+	return func(r *settings) {
+		r.oidcSigners = albARNs
+
+	}
+
+}
+
+// WithALBMutualTLS exposes the client certificate that ALB mutual TLS adds to
+// requests in the X-Amzn-Mtls-Clientcert-Leaf or X-Amzn-Mtls-Clientcert header
+// in Request.TLS.PeerCertificates. Only set it when the listener has mutual
+// TLS enabled: otherwise clients can send these headers themselves.
+func WithALBMutualTLS() Option {
+	return func(r *settings) {
+		r.albMutualTLS = true
+
+	}
+
+}
+
+// WithMethodOverride lets clients behind proxies only allowing GET and POST
+// send PUT, PATCH and DELETE requests as POST requests with the method in the
+// X-HTTP-Method-Override header or the _method field of a url-encoded form.
+// The method is replaced before the request reaches the router.
+func WithMethodOverride() Option {
+	return func(r *settings) {
+		r.methodOverride = true
+
+	}
+
+}
+
+// WithPreservedHeaderCase keeps the header names of the event as received,
+// bypassing the canonicalization of net/http, on the converted request and on
+// the headers of the ALB response that the request also had. Handlers must
+// then read request headers from the Header map with their exact name, since
+// Header.Get looks up canonical names only. Headers the handler sets with
+// non-canonical names directly in the Header map keep them too.
+func WithPreservedHeaderCase() Option {
+	return func(r *settings) {
+		r.preserveHeaderCase = true
+
+	}
+
+}
+
+// WithSingleValueHeaders fills the Headers map of the ALB response instead of
+// MultiValueHeaders, for target groups without multi-value headers enabled.
+// Multiple values of a header are joined with a comma.
+func WithSingleValueHeaders() Option {
+	return func(r *settings) {
+		r.singleValueHeaders = true
+
+	}
+
+}
+
+// WithDualHeaders fills both the Headers and the MultiValueHeaders maps of
+// the ALB response, so that headers reach the client whether or not the
+// target group has multi-value headers enabled. In the Headers map multiple
+// values are joined with a comma, except Set-Cookie which keeps the last one.
+func WithDualHeaders() Option {
+	return func(r *settings) {
+		r.dualHeaders = true
+
+	}
+
+}
+
+// WithForceMultiValueHeaders lists headers, such as Set-Cookie, that are
+// still emitted through MultiValueHeaders in single-value header mode
+// because their values cannot be joined.
+func WithForceMultiValueHeaders(names []string) Option {
+	return func(r *settings) {
+		r.forceMultiValueHeaders = names
+
+	}
+
+}
+
+// WithDefaultOptions answers OPTIONS requests that the router rejects with
+// 404 or 405 with an empty 204 instead. The Allow header is kept when the
+// router provided one. It is a lightweight alternative to a CORS middleware.
+func WithDefaultOptions() Option {
+	return func(r *settings) {
+		r.defaultOptions = true
+
+	}
+
+}
+
+// WithLogger sets the Logger used by the library. Defaults to the standard
+// logger of the log package.
+func WithLogger(logger Logger) Option {
+	return func(r *settings) {
+		r.logger = logger
+
+	}
+
+}
+
+// WithSlog logs through logger. Request errors are logged as records with
+// method, path, status and error attributes; other messages are logged as
+// formatted info records.
+func WithSlog(logger *slog.Logger) Option {
+	return func(r *settings) {
+		r.logger = slogLogger{logger: logger}
+
+	}
+
+}
+
+// WithFeatureFlagHeaderPrefix collects the request headers starting with
+// prefix, for example "X-Feature-", into a map available through
+// GetFeatureFlagsFromContext.
+func WithFeatureFlagHeaderPrefix(prefix string) Option {
+	return func(r *settings) {
+		r.featureFlagPrefix = prefix
+
+	}
+
+}
+
+// WithMaxConcurrency limits to n the number of requests served at the same
+// time by the RequestAccessor. Requests over the limit get an immediate 503.
+// This only matters for runtimes delivering several events concurrently to
+// the same process. An n of 0 or less means no limit.
+func WithMaxConcurrency(n int) Option {
+	return func(r *settings) {
+		r.concurrency = nil
+		if n > 0 {
+			r.concurrency = make(chan struct{}, n)
+
+		}
+
+	}
+
+}
+
+// WithExtensionContentType sets the Content-Type of responses the handler did
+// not set one for from a file extension, using mime.TypeByExtension, instead
+// of sniffing the body. The extension comes from the FileExtensionHintHeader
+// response header or from the request path. This fixes static assets such as
+// .css, .js or .svg files that http.DetectContentType reports as text/plain.
+func WithExtensionContentType() Option {
+	return func(r *settings) {
+		r.extensionContentType = true
+
+	}
+
+}
+
+// WithBodyValidator runs validate on the body of requests with one of the
+// given content types, application/json if none is given, before routing.
+// When validate returns errors the request is answered with a 422 and a JSON
+// body of the form {"errors":[{"field":"...","message":"..."}]}.
+func WithBodyValidator(validate func([]byte) []FieldError, contentTypes ...string) Option {
+	return func(r *settings) {
+		if len(contentTypes) == 0 {
+			contentTypes = []string{"application/json"}
+
+		}
+		r.bodyValidator = validate
+		r.validatedContentTypes = contentTypes
+
+	}
+
+}
+
+// WithEMFMetrics logs, for every request, a CloudWatch Embedded Metric Format
+// line in namespace with the Latency, RequestBodySize, ResponseBodySize and
+// Count metrics and the Method and StatusClass dimensions. Lines are printed
+// to stdout, or passed unmodified to the logger set with WithLogger, which
+// must then write them as-is for CloudWatch to extract the metrics.
+func WithEMFMetrics(namespace string) Option {
+	return func(r *settings) {
+		r.emfNamespace = namespace
+
+	}
+
+}
+
+// WithRateLimiter consults limiter before routing each request. Requests it
+// denies get a 429 with a Retry-After header, computed by limiter when it is
+// a RetryAfterLimiter and 1 second otherwise. Requests are keyed by client IP,
+// or by the header set with WithRateLimitKeyHeader.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(r *settings) {
+		r.rateLimiter = limiter
+
+	}
+
+}
+
+// WithRateLimitKeyHeader keys rate limiting on the value of the given request
+// header, an API key for instance, instead of the client IP.
+func WithRateLimitKeyHeader(name string) Option {
+	return func(r *settings) {
+		r.rateLimitKeyHeader = name
+
+	}
+
+}
+
+// WithDebug logs warnings about handler misbehaviour that is otherwise
+// silent, such as WriteHeader being called more than once or after Write.
+func WithDebug() Option {
+	return func(r *settings) {
+		r.debug = true
+
+	}
+
+}
+
+// WithMaintenanceMode calls check before routing each request and, when it
+// returns true, answers with resp without invoking the handler. check can
+// read an environment variable or a flag shared with the rest of the
+// application. A resp without a status code is sent as a 503.
+func WithMaintenanceMode(check func() bool, resp events.ALBTargetGroupResponse) Option {
+	return func(r *settings) {
+		if resp.StatusCode == 0 {
+			resp.StatusCode = http.StatusServiceUnavailable
+			resp.StatusDescription = strconv.Itoa(http.StatusServiceUnavailable)
+
+		}
+		r.maintenanceCheck = check
+		r.maintenanceResponse = resp
+
+	}
+
+}
+
+// WithContentSecurityPolicy sets the Content-Security-Policy header of
+// text/html responses to policy unless the handler already set one. Other
+// responses, such as JSON API responses, are left untouched.
+func WithContentSecurityPolicy(policy string) Option {
+	return func(r *settings) {
+		r.contentSecurityPolicy = policy
+
+	}
+
+}
+
+// WithPanicRecovery recovers panics of the handler. Handle then returns a 500
+// response along with a *PanicError holding the recovered value and stack,
+// so that code wrapping the adapter can log or alert on the actual panic.
+func WithPanicRecovery() Option {
+	return func(r *settings) {
+		r.recoverPanics = true
+
+	}
+
+}
+
+// WithForwardedHeaderParsing reads the client IP, protocol and host returned
+// by ClientIP, ClientProto and ClientHost from the RFC 7239 Forwarded header.
+// Its values take precedence over the ones of the X-Forwarded-For,
+// X-Forwarded-Proto and X-Forwarded-Host headers. Only the elements added by
+// the WithTrustedProxies are read, so the header is ignored without them.
+func WithForwardedHeaderParsing() Option {
+	return func(r *settings) {
+		r.parseForwarded = true
+
+	}
+
+}
+
+// WithStrictPathValidation rejects with a 400 the requests whose path, raw or
+// percent-decoded, contains null bytes or invalid UTF-8 sequences.
+func WithStrictPathValidation() Option {
+	return func(r *settings) {
+		r.strictPathValidation = true
+
+	}
+
+}
+
+// WithStrictEventValidation checks events before converting them and answers
+// those that cannot describe a valid request, with a missing or invalid method,
+// a path that is not absolute or badly encoded, a body that is not valid
+// base64 while flagged so, or more than 100 headers or 64 KiB of headers,
+// with a 400 response listing the errors as JSON instead of failing the
+// invocation.
+func WithStrictEventValidation() Option {
+	return func(r *settings) {
+		r.strictEventValidation = true
+
+	}
+
+}
+
+// WithSyntheticHost keeps the host of DefaultServerAddress on converted
+// requests. By default, unless GO_API_HOST is set, the Host header of the
+// event, or the API Gateway domain name when it has none, becomes the host of
+// the request and of its URL.
+func WithSyntheticHost() Option {
+	return func(r *settings) {
+		r.syntheticHost = true
+
+	}
+
+}
+
+// WithWarmup answers warm-up pings with an empty 200 without calling the
+// handler, so that warmers do not show up in application logs and metrics.
+// Pings are the payloads of serverless-plugin-warmup and scheduled events
+// without detail received by HandleRaw and, when header is not empty,
+// requests carrying that header.
+func WithWarmup(header string) Option {
+	return func(r *settings) {
+		r.warmup = true
+		r.warmupHeader = header
+
+	}
+
+}
+
+// WithHealthCheck answers ALB health checks, requests to path or sent by the
+// ELB-HealthChecker user agent, directly with a 200 without calling the
+// handler. When ready is not nil it runs first and a failure is answered with
+// a 503. Leave path empty to only match the user agent.
+func WithHealthCheck(path string, ready func(context.Context) error) Option {
+	return func(r *settings) {
+		r.healthCheck = true
+		r.healthCheckPath = path
+		r.readiness = ready
+
+	}
+
+}
+
+// WithDeadlineBuffer sets the deadline of the request context to the Lambda
+// invocation deadline minus buffer, and answers with a 504 when the handler
+// has not responded by then, instead of letting the runtime kill the
+// invocation mid-write. Handlers and GetHTTPClientFromContext see the earlier
+// deadline. Go cannot stop a handler, so one that misses the deadline keeps
+// running in the background, writing to a response that is discarded, and
+// holds its WithMaxConcurrency slot and multipart files until it returns:
+// handlers must give up when the request context is done.
+func WithDeadlineBuffer(buffer time.Duration) Option {
+	return func(r *settings) {
+		r.deadlineBuffer = buffer
+
+	}
+
+}
+
+// WithMetrics collects request, error, latency and byte counters exposed in
+// the Prometheus text format by MetricsHandler.
+func WithMetrics() Option {
+	return func(r *settings) {
+		r.metrics = newMetrics()
+
+	}
+
+}
+
+// WithBinaryContentTypes lists media types, such as application/proto, whose
+// response bodies are always base64 encoded. Other bodies are only base64
+// encoded when they are not valid UTF-8.
+func WithBinaryContentTypes(mediaTypes ...string) Option {
+	return func(r *settings) {
+		r.binaryContentTypes = append(r.binaryContentTypes, mediaTypes...)
+
+	}
+
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// handle serves an event with h once the maintenance mode and the
+// concurrency limit let it through.
+func (r *settings) handle(ctx context.Context, req event, h http.Handler) (events.ALBTargetGroupResponse, error) {
+	resp, release, ok := r.admit(ctx, req)
+	if !ok {
+		return resp, nil
+
+	}
+
+	_, proxyResponse, err := r.serve(ctx, req, h, release)
+	return proxyResponse, err
+
+}
+
+// admit answers, without calling the handler, warm-up requests, health checks,
+// requests received in maintenance mode and requests over the concurrency
+// limit. It returns ok for the other requests, with the function releasing
+// the concurrency slot they took.
+func (r *settings) admit(ctx context.Context, req event) (events.ALBTargetGroupResponse, func(), bool) {
+	start := time.Now()
+	var resp events.ALBTargetGroupResponse
+	switch {
+	case r.isWarmupRequest(req):
+		resp = warmupResponse()
+
+	case r.isHealthCheck(req):
+		resp = r.healthCheckResponse(ctx)
+
+	case r.maintenanceCheck != nil && r.maintenanceCheck():
+		resp = r.maintenanceResponse
+
+	case r.concurrency == nil:
+		return resp, func() {}, true
+
+	default:
+		select {
+		case r.concurrency <- struct{}{}:
+			return resp, func() { <-r.concurrency }, true
+
+		default:
+			resp = statusResponse(http.StatusServiceUnavailable)
+
+		}
+
+	}
+	r.observe(resp.StatusCode, start, eventBodySize(req), len(resp.Body))
+	return resp, nil, false
+
+}
+
+// serve converts req, serves it with h and converts the response. It returns
+// the http.Request that was passed to h, or nil if the conversion failed.
+// release is called once h has returned, which is after serve returns when h
+// outlives the deadline set with WithDeadlineBuffer.
+func (r *settings) serve(ctx context.Context, req event, h http.Handler, release func()) (*http.Request, events.ALBTargetGroupResponse, error) {
+	start := time.Now()
+	ctx, cancel := r.withDeadlineBuffer(ctx)
+	defer cancel()
+	httpRequest, err := r.eventToRequestWithContext(ctx, req)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		release()
+		resp := r.errorResponse(httpErr)
+		r.observe(resp.StatusCode, start, eventBodySize(req), len(resp.Body))
+		return nil, resp, nil
+
+	}
+	if err != nil {
+		release()
+		r.observe(http.StatusGatewayTimeout, start, eventBodySize(req), 0)
+		return nil, TimeoutResponse(), NewLoggedError("Could not convert proxy event to request: %v", err)
+
+	}
+
+	h = r.withHeaderCase(h, req)
+	if r.bodyValidator != nil {
+		h = r.validateBody(h)
+
+	}
+	if r.rateLimiter != nil {
+		h = r.rateLimit(h)
+
+	}
+
+	// The multipart files and the concurrency slot of the request are only
+	// released once h has returned, even past the deadline.
+	finish := func() {
+		removeMultipartForms(httpRequest)
+		release()
+
+	}
+	method, path, requestBytes := httpRequest.Method, httpRequest.URL.Path, httpRequest.ContentLength
+	respWriter := r.NewResponseWriter(path)
+	respWriter.headerNames = r.headerNames(req)
+	err = r.serveBeforeDeadline(h, respWriter, httpRequest, finish)
+	if errors.Is(err, ErrDeadlineExceeded) {
+		// h may still be running and writing to respWriter, which is
+		// discarded, as is httpRequest.
+		r.logRequestError(method, path, http.StatusGatewayTimeout, err)
+		r.observe(http.StatusGatewayTimeout, start, requestBytes, 0)
+		return nil, TimeoutResponse(), nil
+
+	}
+	if err != nil {
+		r.logRequestError(method, path, http.StatusInternalServerError, err)
+		r.observe(http.StatusInternalServerError, start, requestBytes, 0)
+		return httpRequest, statusResponse(http.StatusInternalServerError), err
+
+	}
+	if r.defaultOptions && httpRequest.Method == http.MethodOptions {
+		respWriter = r.defaultOptionsResponse(path, respWriter)
+
+	}
+
+	proxyResponse, err := respWriter.GetProxyResponse()
+	if err != nil {
+		r.observe(http.StatusGatewayTimeout, start, requestBytes, 0)
+		return httpRequest, TimeoutResponse(), NewLoggedError("Error while generating proxy response: %v", err)
+
+	}
+	entry := newAccessLogEntry(httpRequest, respWriter, time.Since(start))
+	r.logAccess(entry)
+	r.emitEMF(entry)
+	r.observe(respWriter.Status(), start, requestBytes, respWriter.Size())
+
+	return httpRequest, proxyResponse, nil
+
+}
+
+// defaultOptionsResponse replaces a 404 or 405 answer to an OPTIONS request
+// with an empty 204, keeping the Allow header when the router set one.
+func (r *settings) defaultOptionsResponse(path string, w *ProxyResponseWriter) *ProxyResponseWriter {
+	if w.status != http.StatusNotFound && w.status != http.StatusMethodNotAllowed {
+		return w
+
+	}
+
+	options := r.NewResponseWriter(path)
+	if allow := w.Header().Get("Allow"); allow != "" {
+		options.Header().Set("Allow", allow)
+
+	}
+	options.WriteHeader(http.StatusNoContent)
+	return options
+
+}
+
+// NewResponseWriter returns a ProxyResponseWriter for a request to path,
+// configured with the response related options of the Accessor.
+func (r *settings) NewResponseWriter(path string) *ProxyResponseWriter {
+	w := NewProxyResponseWriter()
+	w.requestPath = path
+	w.prettyJSON = r.prettyJSON
+	w.singleValueHeaders = r.singleValueHeaders
+	w.dualHeaders = r.dualHeaders
+	w.forceMultiValueHeaders = r.forceMultiValueHeaders
+	w.extensionContentType = r.extensionContentType
+	w.contentSecurityPolicy = r.contentSecurityPolicy
+	w.binaryContentTypes = r.binaryContentTypes
+	if r.debug {
+		w.debugLogger = r.log()
+
+	}
+	return w
+
+}
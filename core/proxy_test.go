@@ -0,0 +1,199 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestMaxConcurrency(t *testing.T) {
+	const n = 2
+	r := NewRequestAccessor(WithMaxConcurrency(n))
+	started := make(chan struct{}, n)
+	release := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+
+	})
+	req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/"}
+
+	var wg sync.WaitGroup
+	statuses := make([]int, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, _ := r.Handle(context.Background(), req, h)
+			statuses[i] = resp.StatusCode
+
+		}(i)
+
+	}
+	for i := 0; i < n; i++ {
+		<-started
+
+	}
+
+	resp, err := r.Handle(context.Background(), req, h)
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("invocation %d: got %d, %v, want 503", n+1, resp.StatusCode, err)
+
+	}
+	close(release)
+	wg.Wait()
+	for i, status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("invocation %d: got %d, want 200", i+1, status)
+
+		}
+
+	}
+
+	resp, _ = r.Handle(context.Background(), req, http.NotFoundHandler())
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("after release: got %d, want 404", resp.StatusCode)
+
+	}
+
+}
+
+func TestMaxConcurrencyUnlimited(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		r := NewRequestAccessor(WithMaxConcurrency(n))
+		req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/"}
+		resp, err := r.Handle(context.Background(), req, http.NotFoundHandler())
+		if err != nil || resp.StatusCode != http.StatusNotFound {
+			t.Errorf("WithMaxConcurrency(%d): got %d, %v, want 404", n, resp.StatusCode, err)
+
+		}
+
+	}
+
+}
+
+func TestDefaultOptions(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+
+	})
+	tests := []struct {
+		name       string
+		opts       []Option
+		path       string
+		wantStatus int
+		wantAllow  string
+	}{
+		{name: "method not allowed", opts: []Option{WithDefaultOptions()}, path: "/items", wantStatus: http.StatusNoContent, wantAllow: "GET, HEAD"},
+		{name: "not found", opts: []Option{WithDefaultOptions()}, path: "/missing", wantStatus: http.StatusNoContent},
+		{name: "disabled", path: "/items", wantStatus: http.StatusMethodNotAllowed, wantAllow: "GET, HEAD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodOptions, Path: tt.path}
+			resp, err := NewRequestAccessor(tt.opts...).Handle(context.Background(), req, mux)
+			if err != nil || resp.StatusCode != tt.wantStatus {
+				t.Fatalf("got %d, %v, want %d", resp.StatusCode, err, tt.wantStatus)
+
+			}
+			if allow := http.Header(resp.MultiValueHeaders).Get("Allow"); allow != tt.wantAllow {
+				t.Errorf("got Allow %q, want %q", allow, tt.wantAllow)
+
+			}
+			if tt.wantStatus == http.StatusNoContent && resp.Body != "" {
+				t.Errorf("got body %q, want none", resp.Body)
+
+			}
+
+		})
+
+	}
+
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	var maintenance atomic.Bool
+	page := events.ALBTargetGroupResponse{
+		StatusCode: http.StatusServiceUnavailable,
+		Headers:    map[string]string{"Retry-After": "120"},
+		Body:       "back soon",
+	}
+	r := NewRequestAccessor(WithMaintenanceMode(maintenance.Load, page))
+	called := false
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+
+	})
+	req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/"}
+
+	maintenance.Store(true)
+	resp, err := r.Handle(context.Background(), req, h)
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || resp.Body != "back soon" || called {
+		t.Errorf("in maintenance: got %d %q, %v, handler called %v, want the maintenance page", resp.StatusCode, resp.Body, err, called)
+
+	}
+
+	maintenance.Store(false)
+	resp, err = r.Handle(context.Background(), req, h)
+	if err != nil || resp.StatusCode != http.StatusOK || !called {
+		t.Errorf("out of maintenance: got %d, %v, want 200 from the handler", resp.StatusCode, err)
+
+	}
+
+}
+
+func TestDryRun(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(append([]byte("got "), body...))
+
+	})
+	req := events.ALBTargetGroupRequest{
+		HTTPMethod:                      http.MethodPost,
+		Path:                            "/orders",
+		MultiValueQueryStringParameters: map[string][]string{"id": {"42"}},
+		MultiValueHeaders:               map[string][]string{"x-request-id": {"abc"}},
+		Body:                            "hello",
+	}
+
+	httpRequest, resp, err := NewRequestAccessor().DryRun(req, h)
+	if err != nil {
+		t.Fatal(err)
+
+	}
+	if httpRequest.Method != http.MethodPost || httpRequest.URL.String() != DefaultServerAddress+"/orders?id=42" || httpRequest.Header.Get("X-Request-Id") != "abc" {
+		t.Errorf("got %s %s %v", httpRequest.Method, httpRequest.URL, httpRequest.Header)
+
+	}
+	body, err := httpRequest.GetBody()
+	if err != nil {
+		t.Fatal(err)
+
+	}
+	if bb, _ := io.ReadAll(body); string(bb) != "hello" {
+		t.Errorf("got body %q, want %q", bb, "hello")
+
+	}
+	want := events.ALBTargetGroupResponse{
+		StatusCode:        http.StatusCreated,
+		StatusDescription: "201",
+		Body:              "got hello",
+		MultiValueHeaders: map[string][]string{"Content-Type": {"text/plain"}},
+	}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("got %+v, want %+v", resp, want)
+
+	}
+
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter decides whether the request identified by key may be served.
+type RateLimiter interface {
+	Allow(key string) bool
+}
+
+// RetryAfterLimiter is implemented by the RateLimiters that can tell how long
+// a denied key has to wait before its next request is allowed.
+type RetryAfterLimiter interface {
+	RetryAfter(key string) time.Duration
+}
+
+// bucketSweepInterval is how often TokenBucketLimiter drops the buckets that
+// refilled, which behave like the new bucket of an unseen key.
+const bucketSweepInterval = time.Minute
+
+// TokenBucketLimiter is an in-memory RateLimiter keeping one token bucket per
+// key. Buckets live as long as the Lambda container, which makes it suited
+// to absorb bursts hitting a warm container rather than for global quotas.
+// Buckets of idle keys are dropped once they refilled.
+type TokenBucketLimiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucketLimiter returns a TokenBucketLimiter allowing rate requests
+// per second per key with bursts of up to burst requests.
+func NewTokenBucketLimiter(rate float64, burst int) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+
+}
+
+// Allow takes a token from the bucket of key and reports whether one was
+// available.
+func (l *TokenBucketLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) >= bucketSweepInterval {
+		l.sweep(now)
+
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+
+	}
+
+	l.refill(b, now)
+	if b.tokens < 1 {
+		return false
+
+	}
+	b.tokens--
+	return true
+
+}
+
+// RetryAfter returns how long key has to wait for the next token.
+func (l *TokenBucketLimiter) RetryAfter(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		return 0
+
+	}
+	l.refill(b, time.Now())
+	if b.tokens >= 1 {
+		return 0
+
+	}
+	if l.rate <= 0 {
+		return time.Duration(math.MaxInt64)
+
+	}
+	return time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+
+}
+
+// refill adds the tokens earned by b since its last use.
+func (l *TokenBucketLimiter) refill(b *tokenBucket, now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+
+	}
+	b.last = now
+
+}
+
+// sweep drops the buckets that are full again.
+func (l *TokenBucketLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+
+		}
+
+	}
+	l.lastSweep = now
+
+}
+
+// rateLimit wraps next so that requests denied by the rate limiter get a 429
+// with a Retry-After header and never reach next.
+func (r *settings) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if r.denyRateLimited(w, req.Header, r.connectionInfo(req).clientIP) {
+			return
+
+		}
+		next.ServeHTTP(w, req)
+
+	})
+
+}
+
+// denyRateLimited answers w with a 429 and a Retry-After header and reports
+// true when the rate limiter denies the request with header sent by clientIP.
+func (r *settings) denyRateLimited(w http.ResponseWriter, header http.Header, clientIP string) bool {
+	key := clientIP
+	if r.rateLimitKeyHeader != "" {
+		key = header.Get(r.rateLimitKeyHeader)
+
+	}
+
+	if r.rateLimiter.Allow(key) {
+		return false
+
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(r.rateLimiter, key)))
+	http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+	return true
+
+}
+
+// retryAfterSeconds returns the whole number of seconds, at least 1, a key
+// denied by limiter has to wait, as told by limiter when it is a
+// RetryAfterLimiter.
+func retryAfterSeconds(limiter RateLimiter, key string) int {
+	l, ok := limiter.(RetryAfterLimiter)
+	if !ok {
+		return 1
+
+	}
+	seconds := math.Ceil(l.RetryAfter(key).Seconds())
+	if seconds < 1 {
+		return 1
+
+	}
+	if seconds > math.MaxInt32 {
+		return math.MaxInt32
+
+	}
+	return int(seconds)
+
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestRateLimiter(t *testing.T) {
+	r := NewRequestAccessor(WithRateLimiter(NewTokenBucketLimiter(0.25, 2)))
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+
+	})
+	req := events.ALBTargetGroupRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/",
+		Headers:    map[string]string{"X-Forwarded-For": "192.0.2.1"},
+	}
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests} {
+		resp, err := r.Handle(context.Background(), req, h)
+		if err != nil || resp.StatusCode != want {
+			t.Fatalf("request %d: got %d, %v, want %d", i+1, resp.StatusCode, err, want)
+
+		}
+		if want != http.StatusTooManyRequests {
+			continue
+
+		}
+		if got := resp.MultiValueHeaders["Retry-After"]; len(got) != 1 || got[0] != "4" {
+			t.Errorf("request %d: Retry-After = %v, want [4]", i+1, got)
+
+		}
+
+	}
+
+	req.Headers = map[string]string{"X-Forwarded-For": "192.0.2.2"}
+	if resp, _ := r.Handle(context.Background(), req, h); resp.StatusCode != http.StatusOK {
+		t.Errorf("other client: got %d, want 200", resp.StatusCode)
+
+	}
+
+}
+
+func TestTokenBucketLimiterEvictsIdleBuckets(t *testing.T) {
+	l := NewTokenBucketLimiter(1, 2)
+	l.Allow("idle")
+	l.Allow("busy")
+	l.Allow("busy")
+
+	// Pretend the idle key was last seen long enough ago for its bucket to
+	// refill, while the busy one was just emptied.
+	l.buckets["idle"].last = time.Now().Add(-10 * time.Second)
+	l.lastSweep = time.Now().Add(-bucketSweepInterval)
+	l.Allow("other")
+
+	if _, ok := l.buckets["idle"]; ok {
+		t.Error("idle bucket was not evicted")
+
+	}
+	if _, ok := l.buckets["busy"]; !ok {
+		t.Error("busy bucket was evicted")
+
+	}
+
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicError is returned by RequestAccessor.Handle when WithPanicRecovery is
+// set and the handler panicked. Use errors.As to inspect the recovered value.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("handler panicked: %v", e.Value)
+
+}
+
+// serveHTTP calls h and, when panic recovery is enabled, converts a panic
+// into a *PanicError.
+func (r *settings) serveHTTP(h http.Handler, w http.ResponseWriter, req *http.Request) (err error) {
+	if r.recoverPanics {
+		defer func() {
+			if v := recover(); v != nil {
+				err = &PanicError{Value: v, Stack: debug.Stack()}
+
+			}
+
+		}()
+
+	}
+	h.ServeHTTP(w, req)
+	return nil
+
+}
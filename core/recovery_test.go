@@ -0,0 +1,42 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	type failure struct{ code int }
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic(failure{code: 42})
+
+	})
+	var lines lineLogger
+	r := NewRequestAccessor(WithPanicRecovery(), WithLogger(&lines))
+	req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/"}
+	resp, err := r.Handle(context.Background(), req, h)
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("got %d, want 500", resp.StatusCode)
+
+	}
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("got error %v, want a *PanicError", err)
+
+	}
+	if panicErr.Value != (failure{code: 42}) {
+		t.Errorf("got recovered value %v, want {42}", panicErr.Value)
+
+	}
+	if !bytes.Contains(panicErr.Stack, []byte("TestPanicRecovery")) {
+		t.Errorf("stack does not include the panicking handler:\n%s", panicErr.Stack)
+
+	}
+
+}
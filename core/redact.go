@@ -0,0 +1,46 @@
+package core
+
+import (
+	"net/url"
+	"strings"
+)
+
+const redactedValue = "***"
+
+// redactQuery returns rawQuery with the values of the redacted query
+// parameters replaced by ***. The order and encoding of the other parameters
+// is left untouched.
+func (r *settings) redactQuery(rawQuery string) string {
+	if len(r.redactedQueryParams) == 0 || rawQuery == "" {
+		return rawQuery
+
+	}
+
+	pairs := strings.Split(rawQuery, "&")
+	for i, pair := range pairs {
+		key := pair
+		if eq := strings.Index(pair, "="); eq >= 0 {
+			key = pair[:eq]
+
+		}
+		if name, err := url.QueryUnescape(key); err == nil && r.isRedactedQueryParam(name) {
+			pairs[i] = key + "=" + redactedValue
+
+		}
+
+	}
+	return strings.Join(pairs, "&")
+
+}
+
+func (r *settings) isRedactedQueryParam(name string) bool {
+	for _, redacted := range r.redactedQueryParams {
+		if strings.EqualFold(redacted, name) {
+			return true
+
+		}
+
+	}
+	return false
+
+}
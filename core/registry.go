@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Adapter is implemented by the framework adapters of this library, through
+// their embedded Accessor and their ProxyWithContext method, and by the ones
+// shipped by other packages.
+type Adapter[E Event, R Response] interface {
+	// EventToRequest converts an event into the http.Request given to the framework.
+	EventToRequest(req E) (*http.Request, error)
+	// WriteResponse converts what the framework wrote into a response.
+	WriteResponse(w *ProxyResponseWriter) (R, error)
+	// ProxyWithContext serves an event with the framework.
+	ProxyWithContext(ctx context.Context, req E) (R, error)
+}
+
+// AdapterFactory builds the Adapter of a framework application, such as an
+// *echo.Echo, configured with opts. It returns an error when app is not an
+// application of its framework.
+type AdapterFactory[E Event, R Response] func(app any, opts ...Option) (Adapter[E, R], error)
+
+type adapterKey struct {
+	name    string
+	factory reflect.Type
+}
+
+var (
+	adaptersMu sync.RWMutex
+	adapters   = make(map[adapterKey]any)
+)
+
+// RegisterAdapter makes the adapter factory of a framework available by the
+// provided name for the events of type E. Adapter packages usually call it
+// from their init function. If RegisterAdapter is called twice with the same
+// name and event type or if factory is nil, it panics.
+func RegisterAdapter[E Event, R Response](name string, factory AdapterFactory[E, R]) {
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+	if factory == nil {
+		panic("core: RegisterAdapter factory is nil")
+
+	}
+	key := adapterKey{name: name, factory: reflect.TypeOf(factory)}
+	if _, dup := adapters[key]; dup {
+		panic("core: RegisterAdapter called twice for adapter " + name)
+
+	}
+	adapters[key] = factory
+
+}
+
+// NewAdapter builds the Adapter registered by the provided name for the
+// events of type E, serving app and configured with opts.
+func NewAdapter[E Event, R Response](name string, app any, opts ...Option) (Adapter[E, R], error) {
+	adaptersMu.RLock()
+	factory, ok := adapters[adapterKey{name: name, factory: reflect.TypeOf(AdapterFactory[E, R](nil))}]
+	adaptersMu.RUnlock()
+	if !ok {
+		var event E
+		return nil, fmt.Errorf("core: unknown adapter %q for %T events (forgotten import?)", name, event)
+
+	}
+	return factory.(AdapterFactory[E, R])(app, opts...)
+
+}
+
+// Adapters returns a sorted list of the names of the registered adapters.
+func Adapters() []string {
+	adaptersMu.RLock()
+	defer adaptersMu.RUnlock()
+	seen := make(map[string]bool)
+	var names []string
+	for key := range adapters {
+		if !seen[key.name] {
+			seen[key.name] = true
+			names = append(names, key.name)
+
+		}
+
+	}
+	sort.Strings(names)
+	return names
+
+}
@@ -6,13 +6,21 @@ package core
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"fmt"
-	"log"
+	"hash"
+	"io"
+	"mime"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambdacontext"
@@ -20,27 +28,155 @@ import (
 
 // CustomHostVariable is the name of the environment variable that contains
 // the custom hostname for the request. If this variable is not set the framework
-// reverts to `DefaultServerAddress`. The value for a custom host should include
+// builds the URL from the X-Forwarded-Proto and Host headers of the event, or
+// the domain name API Gateway received it on, and reverts to `DefaultServerAddress`
+// for what they do not provide. The value for a custom host should include
 // a protocol: http://my-custom.host.com
 const CustomHostVariable = "GO_API_HOST"
 
 // DefaultServerAddress is prepended to the path of each incoming reuqest
 const DefaultServerAddress = "https://aws-serverless-go-api.com"
 
-// RequestAccessor objects give access to custom ALB properties
-// in the request.
-type RequestAccessor struct {
-	stripBasePath string
+// settings holds the options of an Accessor. They do not depend on the
+// event type, so a single Option works with every Accessor.
+type settings struct {
+	stripBasePaths []string
+	hostBasePaths  map[string]string
+	rewriteRules   []RewriteRule
+	serverAddress  string
+
+	maxDecompressionRatio   int
+	maxDecompressedBodySize int64
+	maxRequestBodySize      int64
+	decompressRequests      bool
+	checksumHeader          string
+	checksumHash            func() hash.Hash
+
+	debug                  bool
+	prettyJSON             bool
+	singleValueHeaders     bool
+	dualHeaders            bool
+	forceMultiValueHeaders []string
+	extensionContentType   bool
+	contentSecurityPolicy  string
+	binaryContentTypes     []string
+
+	downstreamClient *http.Client
+
+	accessLog           func(AccessLogEntry)
+	redactedQueryParams []string
+
+	decodedPathRouting bool
+	pathNormalization  PathNormalization
+	rawQueryString     bool
+	methodOverride     bool
+	oidcSigners        []string
+	albMutualTLS       bool
+	defaultOptions     bool
+	parseForwarded     bool
+	trustedProxies     []*net.IPNet
+
+	strictPathValidation  bool
+	strictEventValidation bool
+	syntheticHost         bool
+
+	logger       Logger
+	emfNamespace string
+	metrics      *metrics
+
+	featureFlagPrefix string
+
+	concurrency chan struct{}
+
+	bodyValidator         func([]byte) []FieldError
+	validatedContentTypes []string
+
+	rateLimiter        RateLimiter
+	rateLimitKeyHeader string
+
+	maintenanceCheck    func() bool
+	maintenanceResponse events.ALBTargetGroupResponse
+
+	recoverPanics bool
+
+	warmup       bool
+	warmupHeader string
+
+	preserveHeaderCase bool
+
+	deadlineBuffer time.Duration
+
+	healthCheck     bool
+	healthCheckPath string
+	readiness       func(context.Context) error
 }
 
-// StripBasePath instructs the RequestAccessor object that the given base
+// StripBasePath instructs the Accessor object that the given base
 // path should be removed from the request path before sending it to the
 // framework for routing. This is used when API Gateway is configured with
 // base path mappings in custom domain names.
 // TODO check if this is still needed.
-func (r *RequestAccessor) StripBasePath(basePath string) string {
+func (r *settings) StripBasePath(basePath string) string {
+	newBasePath := normalizeBasePath(basePath)
+	r.stripBasePaths = nil
+	if newBasePath != "" {
+		r.stripBasePaths = []string{newBasePath}
+
+	}
+	return newBasePath
+
+}
+
+// StripBasePaths is StripBasePath for services mounted under several base
+// path mappings, such as /v1 and /api/v1 during a migration. The longest base
+// path matching the request path is removed. It returns the normalized base
+// paths, longest first.
+func (r *settings) StripBasePaths(basePaths []string) []string {
+	r.stripBasePaths = nil
+	for _, basePath := range basePaths {
+		if newBasePath := normalizeBasePath(basePath); newBasePath != "" {
+			r.stripBasePaths = append(r.stripBasePaths, newBasePath)
+
+		}
+
+	}
+	sort.SliceStable(r.stripBasePaths, func(i, j int) bool {
+		return len(r.stripBasePaths[i]) > len(r.stripBasePaths[j])
+
+	})
+	return r.stripBasePaths
+
+}
+
+// basePaths returns the base paths to strip from the path of an event: the
+// one of its host when WithHostBasePaths maps it, the StripBasePaths otherwise.
+func (r *settings) basePaths(req event) []string {
+	if len(r.hostBasePaths) == 0 {
+		return r.stripBasePaths
+
+	}
+	host := req.header().Get("Host")
+	if host == "" {
+		host = req.domainName()
+
+	}
+	basePath, ok := r.hostBasePaths[strings.ToLower(stripPort(host))]
+	if !ok {
+		return r.stripBasePaths
+
+	}
+	if basePath == "" {
+		return nil
+
+	}
+	return []string{basePath}
+
+}
+
+// normalizeBasePath returns basePath with a leading and no trailing slash,
+// or an empty string for a blank base path.
+func normalizeBasePath(basePath string) string {
 	if strings.Trim(basePath, " ") == "" {
-		r.stripBasePath = ""
 		return ""
 
 	}
@@ -55,131 +191,846 @@ func (r *RequestAccessor) StripBasePath(basePath string) string {
 		newBasePath = newBasePath[:len(newBasePath)-1]
 
 	}
-	r.stripBasePath = newBasePath
 	return newBasePath
 
 }
 
-// EventToRequestWithContext converts an ALB event and context into an http.Request object.
-// Returns the populated http request with lambda context, stage variables and ALBTargetGroupRequestContext as part of its context.
-// Access those using GetALBContextFromContext and GetRuntimeContextFromContext functions in this package.
-func (r *RequestAccessor) EventToRequestWithContext(ctx context.Context, req events.ALBTargetGroupRequest) (*http.Request, error) {
-	httpRequest, err := r.EventToRequest(req)
+// eventToRequestWithContext converts an event into an http.Request
+// carrying the request values of ctx and the event.
+func (r *settings) eventToRequestWithContext(ctx context.Context, req event) (*http.Request, error) {
+	httpRequest, err := r.newRequest(req)
+	if err != nil {
+		r.logRequestError(req.HTTPMethod, req.Path, 0, err)
+		return nil, err
+
+	}
+	principal, err := r.albPrincipal(ctx, httpRequest.Header)
+	if err != nil {
+		r.logRequestError(req.HTTPMethod, req.Path, 0, err)
+		return nil, err
+
+	}
+	return r.addToContext(ctx, httpRequest, req, principal), nil
+
+}
+
+// eventToRequest converts an event into an http.Request object.
+func (r *settings) eventToRequest(req event) (*http.Request, error) {
+	httpRequest, err := r.newRequest(req)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 
 	}
-	return addToContext(ctx, httpRequest, req), nil
+	restoreHeaderCase(httpRequest.Header, r.headerNames(req))
+	return httpRequest, nil
 
 }
 
-// EventToRequest converts an ALB event into an http.Request object.
-// Returns the populated request maintaining headers
-func (r *RequestAccessor) EventToRequest(req events.ALBTargetGroupRequest) (*http.Request, error) {
-	decodedBody := []byte(req.Body)
-	if req.IsBase64Encoded {
-		base64Body, err := base64.StdEncoding.DecodeString(req.Body)
-		if err != nil {
+// newRequest converts an event into an http.Request object with canonical
+// header names.
+func (r *settings) newRequest(req event) (*http.Request, error) {
+	if err := r.checkEvent(req); err != nil {
+		return nil, err
+
+	}
+	body, contentLength, err := eventBody(req)
+	if err != nil {
+		return nil, err
+
+	}
+
+	path, err := r.eventPath(req)
+	if err != nil {
+		return nil, err
+
+	}
+	serverAddress, hasCustomAddress := r.targetAddress()
+	queryString := eventQueryString(req, r.rawQueryString && req.isALB())
+
+	// The URL is built field by field rather than parsed from a string so
+	// that the event path can never be mistaken for a query or fragment.
+	httpRequest, err := http.NewRequest(
+		strings.ToUpper(req.HTTPMethod),
+		serverAddress,
+		nil,
+	)
+	if err == nil {
+		err = r.setRequestPath(httpRequest.URL, strings.TrimSuffix(httpRequest.URL.EscapedPath(), "/")+path)
+
+	}
+
+	if err != nil {
+		r.logRequestError(req.HTTPMethod, req.Path, 0, fmt.Errorf("could not convert request to http.Request: %v", err))
+		return nil, err
+
+	}
+	httpRequest.URL.RawQuery = queryString
+	httpRequest.RequestURI = originalRequestURI(req.Path, queryString)
+	httpRequest.Header = req.header()
+	// The length is the one of the decoded body, whatever the event claims,
+	// and an empty body is http.NoBody like on requests read by net/http.
+	httpRequest.ContentLength = contentLength
+	httpRequest.Body = http.NoBody
+	httpRequest.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+	if contentLength > 0 {
+		httpRequest.Body = io.NopCloser(body())
+		httpRequest.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(body()), nil }
+
+	}
+	if r.checksumHash != nil {
+		if err := r.verifyChecksum(httpRequest, body()); err != nil {
+			return nil, err
+
+		}
+
+	}
+	if r.decompressRequests && contentLength > 0 {
+		if err := r.decompressBody(httpRequest, body()); err != nil {
 			return nil, err
+
+		}
+
+	}
+	if !hasCustomAddress && !r.syntheticHost {
+		// Like net/http servers, promote the Host header to Request.Host.
+		host := httpRequest.Header.Get("Host")
+		if host == "" {
+			host = req.domainName()
+
+		}
+		if host != "" {
+			httpRequest.Host = host
+			httpRequest.URL.Host = host
+			httpRequest.Header.Del("Host")
+
 		}
-		decodedBody = base64Body
+
+	}
+	if r.methodOverride {
+		overrideMethod(httpRequest)
+
+	}
+	setForwardedProto(httpRequest, !hasCustomAddress)
+	if certificate := r.clientCertPEM(req, httpRequest.Header); certificate != "" {
+		setPeerCertificates(httpRequest, certificate)
+
+	}
+	return httpRequest, nil
+}
+
+// targetAddress returns the address requests are sent to, and whether it is
+// a custom one set with WithServerAddress or the CustomHostVariable.
+func (r *settings) targetAddress() (string, bool) {
+	if r.serverAddress != "" {
+		return r.serverAddress, true
+
+	}
+	if customAddress, ok := os.LookupEnv(CustomHostVariable); ok {
+		return customAddress, true
+
+	}
+	return DefaultServerAddress, false
+
+}
+
+// checkEvent rejects events whose body is too large and, with
+// WithStrictEventValidation, invalid events.
+func (r *settings) checkEvent(req event) error {
+	if req.bodyTruncated || r.maxRequestBodySize > 0 && eventBodySize(req) > r.maxRequestBodySize {
+		return &HTTPError{StatusCode: http.StatusRequestEntityTooLarge, Message: "request body too large"}
+
+	}
+	if r.strictEventValidation {
+		return validateEvent(req)
+
+	}
+	return nil
+
+}
+
+// overrideMethod replaces the method of POST requests with the one of the
+// X-HTTP-Method-Override header or, for url-encoded forms, of the _method
+// field. Only PUT, PATCH and DELETE can be requested.
+func overrideMethod(req *http.Request) {
+	if req.Method != http.MethodPost {
+		return
+
+	}
+	method := req.Header.Get("X-HTTP-Method-Override")
+	if method == "" {
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get(contentTypeHeaderKey))
+		if mediaType == "application/x-www-form-urlencoded" {
+			var form url.Values
+			if body, err := req.GetBody(); err == nil {
+				data, _ := io.ReadAll(body)
+				form, _ = url.ParseQuery(string(data))
+
+			}
+			method = form.Get("_method")
+
+		}
+
+	}
+	switch method = strings.ToUpper(method); method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		req.Method = method
+
+	}
+
+}
+
+// headerNames maps the canonical form of the header names of an event to the
+// names as received, for WithPreservedHeaderCase. It returns nil when the
+// option is not set.
+func (r *settings) headerNames(req event) map[string]string {
+	if !r.preserveHeaderCase {
+		return nil
+
+	}
+	names := make(map[string]string, len(req.Headers)+len(req.MultiValueHeaders))
+	for name := range req.Headers {
+		names[http.CanonicalHeaderKey(name)] = name
+
+	}
+	for name := range req.MultiValueHeaders {
+		names[http.CanonicalHeaderKey(name)] = name
+
+	}
+	return names
+
+}
+
+// restoreHeaderCase renames the headers of a converted request back to the
+// names received in the event, as given by headerNames.
+func restoreHeaderCase(header http.Header, names map[string]string) {
+	for canonical, name := range names {
+		if values, ok := header[canonical]; ok && name != canonical {
+			delete(header, canonical)
+			header[name] = values
+
+		}
+
+	}
+
+}
+
+// withHeaderCase wraps h so that it sees the request headers with the names
+// received in the event, while the library reads them by canonical name
+// before and after h runs.
+func (r *settings) withHeaderCase(h http.Handler, req event) http.Handler {
+	names := r.headerNames(req)
+	if names == nil {
+		return h
+
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, httpRequest *http.Request) {
+		restoreHeaderCase(httpRequest.Header, names)
+		defer func() {
+			for name, values := range httpRequest.Header {
+				if canonical := http.CanonicalHeaderKey(name); canonical != name {
+					delete(httpRequest.Header, name)
+					httpRequest.Header[canonical] = values
+
+				}
+
+			}
+
+		}()
+		h.ServeHTTP(w, httpRequest)
+
+	})
+
+}
+
+// setForwardedProto marks requests the client sent over HTTPS, according to
+// the last X-Forwarded-Proto hop, the one added by the load balancer, with a
+// minimal TLS connection state so that scheme-aware code such as secure
+// cookies behaves as behind a real server.
+// The URL scheme follows the header unless setScheme is false.
+func setForwardedProto(req *http.Request, setScheme bool) {
+	proto := strings.ToLower(lastHop(req.Header.Get("X-Forwarded-Proto")))
+	if proto != "http" && proto != "https" {
+		return
+
+	}
+	if setScheme {
+		req.URL.Scheme = proto
+
+	}
+	if proto == "https" {
+		req.TLS = &tls.ConnectionState{
+			HandshakeComplete: true,
+			ServerName:        stripPort(req.Host),
+		}
+
+	} else {
+		req.TLS = nil
+
+	}
+
+}
+
+// eventRequestURI returns the path and query string of an event.
+func (r *settings) eventRequestURI(req event) (string, error) {
+	path, err := r.eventPath(req)
+	if err != nil {
+		return "", err
+
+	}
+	if queryString := eventQueryString(req, r.rawQueryString && req.isALB()); queryString != "" {
+		path += "?" + queryString
+
+	}
+	return path, nil
+
+}
+
+// eventBody returns a function opening the body of an event and the length
+// of the body. The body is read from the event string, and base64 decoded on
+// the fly when the event says so, rather than copied into a decoded slice, so
+// that large uploads are not held twice in memory. Invalid base64 is then
+// reported by the reads of the body.
+func eventBody(req event) (func() io.Reader, int64, error) {
+	if !req.IsBase64Encoded {
+		return func() io.Reader { return strings.NewReader(req.Body) }, int64(len(req.Body)), nil
+
+	}
+
+	if n := base64DecodedLen(req.Body); n >= 0 {
+		return func() io.Reader {
+			return base64.NewDecoder(base64.StdEncoding, strings.NewReader(req.Body))
+
+		}, n, nil
+
+	}
+	// The length of bodies with line breaks is only known once decoded.
+	decoded, err := base64.StdEncoding.DecodeString(req.Body)
+	if err != nil {
+		return nil, 0, err
+
+	}
+	return func() io.Reader { return bytes.NewReader(decoded) }, int64(len(decoded)), nil
+
+}
+
+// eventBodySize returns the size of the decoded body of an event without
+// decoding it, estimated from the base64 length when it cannot be told exactly.
+func eventBodySize(req event) int64 {
+	if !req.IsBase64Encoded {
+		return int64(len(req.Body))
+
+	}
+	if n := base64DecodedLen(req.Body); n >= 0 {
+		return n
+
+	}
+	return int64(len(req.Body) / 4 * 3)
+
+}
+
+// base64DecodedLen returns the length of the decoded form of the padded
+// base64 string s, or -1 if it cannot be told without decoding s.
+func base64DecodedLen(s string) int64 {
+	if len(s)%4 != 0 || strings.ContainsAny(s, "\r\n") {
+		return -1
+
+	}
+	n := int64(len(s) / 4 * 3)
+	if strings.HasSuffix(s, "==") {
+		return n - 2
+
+	}
+	if strings.HasSuffix(s, "=") {
+		return n - 1
+
+	}
+	return n
+
+}
+
+// decodeEventBody returns the body of an event, base64 decoded when the
+// event says so.
+func decodeEventBody(req event) ([]byte, error) {
+	if !req.IsBase64Encoded {
+		return []byte(req.Body), nil
+
+	}
+	return base64.StdEncoding.DecodeString(req.Body)
+
+}
+
+// eventPath returns the still percent-encoded path of the event, validated
+// and stripped of the base path.
+func (r *settings) eventPath(req event) (string, error) {
+	if r.strictPathValidation {
+		if err := validatePath(req.Path); err != nil {
+			return "", err
+
+		}
+
 	}
 
 	path := req.Path
-	if r.stripBasePath != "" && len(r.stripBasePath) > 1 {
-		if strings.HasPrefix(path, r.stripBasePath) {
-			path = strings.Replace(path, r.stripBasePath, "", 1)
+	if r.pathNormalization != 0 {
+		normalized := normalizeSlashes(path)
+		if normalized != path && r.pathNormalization == NormalizeRedirect {
+			return "", redirect(req, originalRequestURI(normalized, eventQueryString(req, r.rawQueryString && req.isALB())))
+
+		}
+		path = normalized
+
+	}
+	for _, basePath := range r.basePaths(req) {
+		if len(basePath) <= 1 {
+			continue
+
+		}
+		if trimmed := trimBasePath(path, basePath); trimmed != path {
+			path = trimmed
+			break
 
 		}
 
 	}
+	path = r.rewritePath(path)
 	if !strings.HasPrefix(path, "/") {
 		path = "/" + path
 
 	}
-	serverAddress := DefaultServerAddress
-	if customAddress, ok := os.LookupEnv(CustomHostVariable); ok {
-		serverAddress = customAddress
+	return path, nil
+
+}
+
+// originalRequestURI returns the request target as sent by the client, the
+// encoded path before any base path is stripped and the query string, like
+// the RequestURI of requests read by net/http servers.
+func originalRequestURI(path string, queryString string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+
+	}
+	if queryString != "" {
+		path += "?" + queryString
+
+	}
+	return path
+
+}
+
+// eventQueryString returns the encoded query string of the event. With raw,
+// names and values are taken verbatim instead of being escaped, for events
+// such as ALB ones whose parameters are still URL-encoded as received.
+func eventQueryString(req event, raw bool) string {
+	if req.rawQuery != "" {
+		return req.rawQuery
 
 	}
-	path = serverAddress + path
 
+	escape := url.QueryEscape
+	if raw {
+		escape = func(s string) string { return s }
+
+	}
+	// Names are sorted so that the query string, which signed URLs and cache
+	// keys depend on, is the same on every invocation.
+	queryString := ""
 	if len(req.MultiValueQueryStringParameters) > 0 {
-		queryString := ""
-		for q, l := range req.MultiValueQueryStringParameters {
-			for _, v := range l {
+		for _, q := range sortedKeys(req.MultiValueQueryStringParameters) {
+			for _, v := range req.MultiValueQueryStringParameters[q] {
 				if queryString != "" {
 					queryString += "&"
 
 				}
-				queryString += url.QueryEscape(q) + "=" + url.QueryEscape(v)
+				queryString += escape(q) + "=" + escape(v)
 
 			}
 
 		}
-		path += "?" + queryString
 
 	} else if len(req.QueryStringParameters) > 0 {
 		// Support `QueryStringParameters` for backward compatibility.
 		// https://github.com/awslabs/aws-lambda-go-api-proxy/issues/37
-		queryString := ""
-		for q := range req.QueryStringParameters {
+		for _, q := range sortedKeys(req.QueryStringParameters) {
 			if queryString != "" {
 				queryString += "&"
 
 			}
-			queryString += url.QueryEscape(q) + "=" + url.QueryEscape(req.QueryStringParameters[q])
+			queryString += escape(q) + "=" + escape(req.QueryStringParameters[q])
 
 		}
-		path += "?" + queryString
 
 	}
+	return queryString
 
-	httpRequest, err := http.NewRequest(
-		strings.ToUpper(req.HTTPMethod),
-		path,
-		bytes.NewReader(decodedBody),
-	)
+}
 
+// sortedKeys returns the keys of m in increasing order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+
+	}
+	sort.Strings(keys)
+	return keys
+
+}
+
+// validatePath rejects paths which, raw or decoded, contain null bytes or
+// invalid UTF-8 sequences.
+func validatePath(path string) error {
+	decoded, err := url.PathUnescape(path)
 	if err != nil {
-		fmt.Printf("Could not convert request %s:%s to http.Request\n", req.HTTPMethod, req.Path)
-		log.Println(err)
-		return nil, err
+		return badRequest("invalid path encoding")
 
 	}
-	for h := range req.Headers {
-		httpRequest.Header.Add(h, req.Headers[h])
+	for _, p := range []string{path, decoded} {
+		if strings.ContainsRune(p, 0) {
+			return badRequest("path contains a null byte")
+
+		}
+		if !utf8.ValidString(p) {
+			return badRequest("path contains invalid UTF-8")
+
+		}
 
 	}
-	return httpRequest, nil
+	return nil
+
+}
+
+// setRequestPath sets the path of u from rawPath, the percent-encoded path
+// received in the event. URL.Path always holds the decoded path. URL.RawPath
+// holds rawPath when its encoding differs from the default one, as with
+// net/http servers, or, with WithDecodedPathRouting, unconditionally.
+func (r *settings) setRequestPath(u *url.URL, rawPath string) error {
+	decoded, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return err
+
+	}
+
+	u.Path = decoded
+	u.RawPath = ""
+	if r.decodedPathRouting || u.EscapedPath() != rawPath {
+		u.RawPath = rawPath
+
+	}
+	return nil
+
+}
+
+// normalizeSlashes collapses the runs of slashes of path and removes its
+// trailing slash.
+func normalizeSlashes(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '/' && i > 0 && path[i-1] == '/' {
+			continue
+
+		}
+		b.WriteByte(path[i])
+
+	}
+	normalized := b.String()
+	if len(normalized) > 1 {
+		normalized = strings.TrimSuffix(normalized, "/")
+
+	}
+	return normalized
+
+}
+
+// redirect returns the *HTTPError redirecting the request of an event to
+// location, permanently: 301 for GET and HEAD requests, 308 for the others so
+// that clients repeat the same method.
+func redirect(req event, location string) *HTTPError {
+	status := http.StatusPermanentRedirect
+	if method := strings.ToUpper(req.HTTPMethod); method == http.MethodGet || method == http.MethodHead {
+		status = http.StatusMovedPermanently
+
+	}
+	return &HTTPError{
+		StatusCode: status,
+		Message:    http.StatusText(status),
+		Header:     http.Header{"Location": {location}},
+	}
+
+}
+
+// trimBasePath removes basePath from path only when it matches whole path
+// segments. Matrix parameters (/items;color=red/42) are part of the segment
+// they follow, so a base path of /items must not eat into /items;color=red.
+func trimBasePath(path string, basePath string) string {
+	if !strings.HasPrefix(path, basePath) {
+		return path
+
+	}
+	rest := path[len(basePath):]
+	if rest != "" && rest[0] != '/' {
+		return path
+
+	}
+	return rest
+
+}
+
+func (r *settings) addToContext(ctx context.Context, req *http.Request, ev event, principal *Principal) *http.Request {
+	return req.WithContext(r.newRequestContext(ctx, ev, req.Header, principal))
+
+}
+
+// eventContext returns a copy of ctx carrying the request values of an event.
+func (r *settings) eventContext(ctx context.Context, req event) context.Context {
+	return r.newRequestContext(ctx, req, req.header(), nil)
+
 }
 
-func addToContext(ctx context.Context, req *http.Request, albRequest events.ALBTargetGroupRequest) *http.Request {
+func (r *settings) newRequestContext(ctx context.Context, req event, header http.Header, principal *Principal) context.Context {
 	lc, _ := lambdacontext.FromContext(ctx)
-	rc := requestContext{lambdaContext: lc, albContext: albRequest.RequestContext}
-	ctx = context.WithValue(ctx, ctxKey{}, rc)
-	return req.WithContext(ctx)
+	values := requestValues{}
+	setValue(values, lc)
+	setValue(values, req.RequestContext)
+	setValue(values, &multipartForms{})
+	if req.apiGatewayContext != nil {
+		setValue(values, *req.apiGatewayContext)
+
+	}
+	if req.httpAPIContext != nil {
+		setValue(values, *req.httpAPIContext)
+
+	}
+	if req.functionURLContext != nil {
+		setValue(values, *req.functionURLContext)
+
+	}
+	if req.cloudFrontConfig != nil {
+		setValue(values, *req.cloudFrontConfig)
+
+	}
+	if req.latticeContext != nil {
+		setValue(values, *req.latticeContext)
+
+	}
+	if req.stage != "" {
+		setValue(values, Stage(req.stage))
+
+	}
+	if len(req.pathParameters) > 0 {
+		setValue(values, PathParameters(req.pathParameters))
+
+	}
+	if len(req.stageVariables) > 0 {
+		setValue(values, StageVariables(req.stageVariables))
+
+	}
+	if r.downstreamClient != nil {
+		setValue(values, downstreamClient{r.downstreamClient})
+
+	}
+	if flags := r.featureFlags(header); flags != nil {
+		setValue(values, FeatureFlags(flags))
+
+	}
+	if principal != nil {
+		setValue(values, *principal)
+
+	}
+	if req.raw != nil {
+		setValue(values, rawEvent{req.raw})
+		values[reflect.TypeOf(req.raw)] = req.raw
+
+	}
+	return context.WithValue(ctx, ctxKey{}, values)
+
+}
+
+// CopyRequestContext returns a copy of ctx carrying the ALB, Lambda runtime
+// and other request values attached by the RequestAccessor to from. It lets
+// frameworks and background work that start from a fresh context keep access
+// to the GetXFromContext accessors.
+func CopyRequestContext(ctx context.Context, from context.Context) context.Context {
+	v, ok := from.Value(ctxKey{}).(requestValues)
+	if !ok {
+		return ctx
+
+	}
+	return context.WithValue(ctx, ctxKey{}, v)
 
 }
 
 // GetALBContextFromContext retrieve ALBTargetGroupRequestContext from context.Context
 func GetALBContextFromContext(ctx context.Context) (events.ALBTargetGroupRequestContext, bool) {
-	v, ok := ctx.Value(ctxKey{}).(requestContext)
-	return v.albContext, ok
+	return FromContext[events.ALBTargetGroupRequestContext](ctx)
+
+}
+
+// GetAPIGatewayContextFromContext retrieve APIGatewayProxyRequestContext from context.Context.
+// Returns false for requests that did not come from an API Gateway REST API.
+func GetAPIGatewayContextFromContext(ctx context.Context) (events.APIGatewayProxyRequestContext, bool) {
+	return FromContext[events.APIGatewayProxyRequestContext](ctx)
+
+}
+
+// GetHTTPAPIContextFromContext retrieve APIGatewayV2HTTPRequestContext from context.Context.
+// Its RouteKey is the route that matched the request.
+// Returns false for requests that did not come from an API Gateway HTTP API.
+func GetHTTPAPIContextFromContext(ctx context.Context) (events.APIGatewayV2HTTPRequestContext, bool) {
+	return FromContext[events.APIGatewayV2HTTPRequestContext](ctx)
+
+}
+
+// GetFunctionURLContextFromContext retrieve LambdaFunctionURLRequestContext from context.Context.
+// Returns false for requests that did not come from a Lambda Function URL.
+func GetFunctionURLContextFromContext(ctx context.Context) (events.LambdaFunctionURLRequestContext, bool) {
+	return FromContext[events.LambdaFunctionURLRequestContext](ctx)
+
+}
+
+// GetCloudFrontConfigFromContext retrieve the CloudFrontConfig of a Lambda@Edge
+// event from context.Context. Returns false for requests that did not come from CloudFront.
+func GetCloudFrontConfigFromContext(ctx context.Context) (CloudFrontConfig, bool) {
+	return FromContext[CloudFrontConfig](ctx)
+
+}
+
+// GetVPCLatticeContextFromContext retrieve the VPCLatticeRequestContext of a
+// version 2.0 VPC Lattice event from context.Context. Returns false for other requests.
+func GetVPCLatticeContextFromContext(ctx context.Context) (VPCLatticeRequestContext, bool) {
+	return FromContext[VPCLatticeRequestContext](ctx)
 
 }
 
 // GetRuntimeContextFromContext retrieve Lambda Runtime Context from context.Context
 func GetRuntimeContextFromContext(ctx context.Context) (*lambdacontext.LambdaContext, bool) {
-	v, ok := ctx.Value(ctxKey{}).(requestContext)
-	return v.lambdaContext, ok
+	return FromContext[*lambdacontext.LambdaContext](ctx)
 
 }
 
-type ctxKey struct{}
+// GetRawEventFromContext retrieve the event the request was converted from,
+// such as an events.ALBTargetGroupRequest, from context.Context, for the
+// fields the conversion does not map. FromContext retrieves it by type.
+func GetRawEventFromContext(ctx context.Context) (interface{}, bool) {
+	v, ok := FromContext[rawEvent](ctx)
+	return v.event, ok
 
-type requestContext struct {
-	lambdaContext *lambdacontext.LambdaContext
-	albContext    events.ALBTargetGroupRequestContext
 }
+
+// GetClientContextFromContext retrieve the client context the AWS Mobile SDK
+// sent with the invocation from context.Context.
+// Returns false for invocations without client context.
+func GetClientContextFromContext(ctx context.Context) (lambdacontext.ClientContext, bool) {
+	lc, ok := FromContext[*lambdacontext.LambdaContext](ctx)
+	if !ok || lc == nil {
+		return lambdacontext.ClientContext{}, false
+
+	}
+	cc := lc.ClientContext
+	if cc.Client == (lambdacontext.ClientApplication{}) && len(cc.Env) == 0 && len(cc.Custom) == 0 {
+		return lambdacontext.ClientContext{}, false
+
+	}
+	return cc, true
+
+}
+
+// GetCognitoIdentityFromContext retrieve the Amazon Cognito identity that
+// invoked the function through the AWS Mobile SDK from context.Context.
+// Returns false for invocations without Cognito identity.
+func GetCognitoIdentityFromContext(ctx context.Context) (lambdacontext.CognitoIdentity, bool) {
+	lc, ok := FromContext[*lambdacontext.LambdaContext](ctx)
+	if !ok || lc == nil || lc.Identity.CognitoIdentityID == "" {
+		return lambdacontext.CognitoIdentity{}, false
+
+	}
+	return lc.Identity, true
+
+}
+
+// GetStageFromContext retrieve the API Gateway stage name from context.Context.
+// Returns false for events that have no stage, such as ALB events.
+func GetStageFromContext(ctx context.Context) (string, bool) {
+	stage, ok := FromContext[Stage](ctx)
+	return string(stage), ok
+
+}
+
+// GetStageVariablesFromContext retrieve the stage variables of the API Gateway
+// stage that received the request from context.Context.
+// Returns false for events without stage variables, such as ALB events.
+func GetStageVariablesFromContext(ctx context.Context) (map[string]string, bool) {
+	variables, ok := FromContext[StageVariables](ctx)
+	return variables, ok
+
+}
+
+// GetPathParametersFromContext retrieve the path parameters API Gateway
+// extracted from the request path with the route of the API, such as id for
+// /items/{id}. Returns false for events without path parameters.
+func GetPathParametersFromContext(ctx context.Context) (map[string]string, bool) {
+	params, ok := FromContext[PathParameters](ctx)
+	return params, ok
+
+}
+
+// GetHTTPClientFromContext returns an *http.Client for downstream calls whose
+// Timeout never exceeds the time left before the context deadline, which is the
+// Lambda invocation deadline for converted requests. The client is a copy of the
+// one configured with WithDownstreamClient, or of http.DefaultClient.
+func GetHTTPClientFromContext(ctx context.Context) *http.Client {
+	base := http.DefaultClient
+	if v, ok := FromContext[downstreamClient](ctx); ok {
+		base = v.Client
+
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return base
+
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		// A zero Timeout means no timeout at all; fail fast instead.
+		remaining = time.Nanosecond
+
+	}
+	client := *base
+	if client.Timeout == 0 || remaining < client.Timeout {
+		client.Timeout = remaining
+
+	}
+	return &client
+
+}
+
+// GetFeatureFlagsFromContext retrieve the feature flags collected from the
+// headers matching the prefix set with WithFeatureFlagHeaderPrefix. Flag
+// names are the lower-cased header names without the prefix.
+func GetFeatureFlagsFromContext(ctx context.Context) (map[string]string, bool) {
+	flags, ok := FromContext[FeatureFlags](ctx)
+	return flags, ok
+
+}
+
+// featureFlags collects the headers starting with the feature flag prefix.
+func (r *settings) featureFlags(header http.Header) map[string]string {
+	if r.featureFlagPrefix == "" {
+		return nil
+
+	}
+
+	prefix := http.CanonicalHeaderKey(r.featureFlagPrefix)
+	flags := make(map[string]string)
+	for name, values := range header {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) && len(values) > 0 {
+			flags[strings.ToLower(name[len(prefix):])] = values[0]
+
+		}
+
+	}
+	return flags
+
+}
+
+type ctxKey struct{}
@@ -0,0 +1,301 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestDecodedPathRouting(t *testing.T) {
+	var path, rawPath string
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path, rawPath = req.URL.Path, req.URL.RawPath
+		w.WriteHeader(http.StatusNoContent)
+
+	})
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		path        string
+		wantPath    string
+		wantRawPath string
+	}{
+		{name: "default", path: "/files/a%2Fb", wantPath: "/files/a/b", wantRawPath: "/files/a%2Fb"},
+		{name: "decoded", opts: []Option{WithDecodedPathRouting()}, path: "/files/a%2Fb", wantPath: "/files/a/b", wantRawPath: "/files/a%2Fb"},
+		{name: "default encoding", path: "/files/caf%C3%A9", wantPath: "/files/café"},
+		{name: "default encoding decoded", opts: []Option{WithDecodedPathRouting()}, path: "/files/caf%C3%A9", wantPath: "/files/café", wantRawPath: "/files/caf%C3%A9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: tt.path}
+			if _, err := NewRequestAccessor(tt.opts...).Handle(context.Background(), req, h); err != nil {
+				t.Fatal(err)
+
+			}
+			if path != tt.wantPath || rawPath != tt.wantRawPath {
+				t.Errorf("got %q %q, want %q %q", path, rawPath, tt.wantPath, tt.wantRawPath)
+
+			}
+
+		})
+
+	}
+
+}
+
+func TestMatrixParameters(t *testing.T) {
+	var path, escapedPath string
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path, escapedPath = req.URL.Path, req.URL.EscapedPath()
+		w.WriteHeader(http.StatusOK)
+
+	})
+	req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/items;color=red;size=l/42"}
+	if _, err := NewRequestAccessor().Handle(context.Background(), req, h); err != nil {
+		t.Fatal(err)
+
+	}
+	if path != req.Path || escapedPath != req.Path {
+		t.Errorf("got Path %q and EscapedPath %q, want %q", path, escapedPath, req.Path)
+
+	}
+
+}
+
+func TestGetStageFromContext(t *testing.T) {
+	var stage string
+	var hasStage bool
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		stage, hasStage = GetStageFromContext(req.Context())
+		w.WriteHeader(http.StatusOK)
+
+	})
+
+	alb := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/"}
+	if _, err := NewRequestAccessor().Handle(context.Background(), alb, h); err != nil {
+		t.Fatal(err)
+
+	}
+	if hasStage {
+		t.Errorf("ALB: got stage %q, want none", stage)
+
+	}
+
+	apiGateway := events.APIGatewayProxyRequest{
+		HTTPMethod:     http.MethodGet,
+		Path:           "/",
+		RequestContext: events.APIGatewayProxyRequestContext{Stage: "prod"},
+	}
+	r := NewAccessor[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse]()
+	if _, err := r.Handle(context.Background(), apiGateway, h); err != nil {
+		t.Fatal(err)
+
+	}
+	if !hasStage || stage != "prod" {
+		t.Errorf("API Gateway: got %q, %v, want prod", stage, hasStage)
+
+	}
+
+}
+
+func TestGetHTTPClientFromContext(t *testing.T) {
+	base := &http.Client{Timeout: 10 * time.Second}
+	r := NewRequestAccessor(WithDownstreamClient(base))
+	req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/"}
+	timeout := func(left time.Duration) time.Duration {
+		var client *http.Client
+		h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			client = GetHTTPClientFromContext(req.Context())
+			w.WriteHeader(http.StatusOK)
+
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), left)
+		defer cancel()
+		if _, err := r.Handle(ctx, req, h); err != nil {
+			t.Fatal(err)
+
+		}
+		if client == base {
+			t.Fatal("got the configured client itself, want a copy")
+
+		}
+		return client.Timeout
+
+	}
+
+	if got := timeout(time.Hour); got != base.Timeout {
+		t.Errorf("far from the deadline: got %v, want %v", got, base.Timeout)
+
+	}
+	if got := timeout(time.Second); got <= 0 || got > time.Second {
+		t.Errorf("1s before the deadline: got %v, want at most 1s", got)
+
+	}
+
+}
+
+func TestFeatureFlags(t *testing.T) {
+	var flags map[string]string
+	var ok bool
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		flags, ok = GetFeatureFlagsFromContext(req.Context())
+		w.WriteHeader(http.StatusOK)
+
+	})
+	req := events.ALBTargetGroupRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/",
+		Headers: map[string]string{
+			"x-feature-new-checkout": "on",
+			"x-feature-dark-mode":    "beta",
+			"x-request-id":           "42",
+		},
+	}
+	r := NewRequestAccessor(WithFeatureFlagHeaderPrefix("X-Feature-"))
+	if _, err := r.Handle(context.Background(), req, h); err != nil {
+		t.Fatal(err)
+
+	}
+	if !ok || len(flags) != 2 || flags["new-checkout"] != "on" || flags["dark-mode"] != "beta" {
+		t.Errorf("got %v, %v, want map[dark-mode:beta new-checkout:on]", flags, ok)
+
+	}
+
+}
+
+func TestStrictPathValidation(t *testing.T) {
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/files/a%00b", wantStatus: http.StatusBadRequest},
+		{path: "/files/a\x00b", wantStatus: http.StatusBadRequest},
+		{path: "/files/a%ffb", wantStatus: http.StatusBadRequest},
+		{path: "/files/caf%C3%A9", wantStatus: http.StatusOK},
+	}
+	r := NewRequestAccessor(WithStrictPathValidation())
+	for _, tt := range tests {
+		called := false
+		h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			called = true
+			w.WriteHeader(http.StatusOK)
+
+		})
+		req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: tt.path}
+		resp, err := r.Handle(context.Background(), req, h)
+		if err != nil || resp.StatusCode != tt.wantStatus || called != (tt.wantStatus == http.StatusOK) {
+			t.Errorf("%q: got %d, %v, handler called %v, want %d", tt.path, resp.StatusCode, err, called, tt.wantStatus)
+
+		}
+
+	}
+
+}
+
+func TestStableQueryOrder(t *testing.T) {
+	var rawQuery string
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rawQuery = req.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+
+	})
+	r := NewRequestAccessor()
+	tests := []struct {
+		name string
+		req  events.ALBTargetGroupRequest
+		want string
+	}{
+		{
+			name: "single-value",
+			req: events.ALBTargetGroupRequest{
+				QueryStringParameters: map[string]string{"e": "5", "b": "2", "d": "4", "a": "1", "c": "3", "f": "6"},
+			},
+			want: "a=1&b=2&c=3&d=4&e=5&f=6",
+		},
+		{
+			name: "multi-value",
+			req: events.ALBTargetGroupRequest{
+				MultiValueQueryStringParameters: map[string][]string{"z": {"2", "1"}, "y": {"b"}, "x": {"c", "a"}, "w": {"0"}},
+			},
+			want: "w=0&x=c&x=a&y=b&z=2&z=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.HTTPMethod, tt.req.Path = http.MethodGet, "/"
+			for i := 0; i < 20; i++ {
+				if _, err := r.Handle(context.Background(), tt.req, h); err != nil {
+					t.Fatal(err)
+
+				}
+				if rawQuery != tt.want {
+					t.Fatalf("invocation %d: got %q, want %q", i+1, rawQuery, tt.want)
+
+				}
+
+			}
+
+		})
+
+	}
+
+	httpAPI := NewAccessor[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse]()
+	req := events.APIGatewayV2HTTPRequest{
+		RawPath:        "/",
+		RawQueryString: "b=2&a=1&b=1",
+		RequestContext: events.APIGatewayV2HTTPRequestContext{HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: http.MethodGet}},
+	}
+	if _, err := httpAPI.Handle(context.Background(), req, h); err != nil {
+		t.Fatal(err)
+
+	}
+	if rawQuery != req.RawQueryString {
+		t.Errorf("HTTP API: got %q, want the original %q", rawQuery, req.RawQueryString)
+
+	}
+
+}
+
+func TestForwardedProtoLastHop(t *testing.T) {
+	var scheme string
+	var hasTLS bool
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		scheme, hasTLS = req.URL.Scheme, req.TLS != nil
+		w.WriteHeader(http.StatusNoContent)
+
+	})
+
+	tests := []struct {
+		name       string
+		proto      string
+		wantScheme string
+		wantTLS    bool
+	}{
+		{name: "spoofed https", proto: "https, http", wantScheme: "http"},
+		{name: "https", proto: "http, https", wantScheme: "https", wantTLS: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := events.ALBTargetGroupRequest{
+				HTTPMethod:        http.MethodGet,
+				Path:              "/",
+				MultiValueHeaders: map[string][]string{"x-forwarded-proto": {tt.proto}},
+			}
+			if _, err := NewRequestAccessor().Handle(context.Background(), req, h); err != nil {
+				t.Fatal(err)
+
+			}
+			if scheme != tt.wantScheme || hasTLS != tt.wantTLS {
+				t.Errorf("got %q and TLS %v, want %q and TLS %v", scheme, hasTLS, tt.wantScheme, tt.wantTLS)
+
+			}
+
+		})
+
+	}
+
+}
@@ -6,9 +6,13 @@ package core
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"mime"
 	"net/http"
+	"path"
 	"strconv"
+	"strings"
 	"unicode/utf8"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -16,6 +20,12 @@ import (
 
 const defaultStatusCode = -1
 const contentTypeHeaderKey = "Content-Type"
+const cspHeaderKey = "Content-Security-Policy"
+
+// FileExtensionHintHeader is a response header handlers can set to the
+// extension of the file they serve when WithExtensionContentType is enabled.
+// It is used to pick the Content-Type and removed from the response.
+const FileExtensionHintHeader = "X-File-Ext"
 
 // ProxyResponseWriter implements http.ResponseWriter and adds the method
 // necessary to return an events.ALBTargetGroupResponse object
@@ -23,6 +33,25 @@ type ProxyResponseWriter struct {
 	headers http.Header
 	body    bytes.Buffer
 	status  int
+
+	prettyJSON bool
+
+	singleValueHeaders     bool
+	dualHeaders            bool
+	forceMultiValueHeaders []string
+
+	extensionContentType bool
+	requestPath          string
+
+	contentSecurityPolicy string
+	binaryContentTypes    []string
+
+	writeHeaderCalls int
+	wroteBody        bool
+	debugLogger      Logger
+
+	// headerNames maps canonical header names to the names to use instead.
+	headerNames map[string]string
 }
 
 // NewProxyResponseWriter returns a new ProxyResponseWriter object.
@@ -46,6 +75,7 @@ func (r *ProxyResponseWriter) Header() http.Header {
 // was set before with the WriteHeader method it sets the status
 // for the response to 200 OK.
 func (r *ProxyResponseWriter) Write(body []byte) (int, error) {
+	r.wroteBody = true
 	if r.status == -1 {
 		r.status = http.StatusOK
 
@@ -54,9 +84,11 @@ func (r *ProxyResponseWriter) Write(body []byte) (int, error) {
 	// if the content type header is not set when we write the body we try to
 	// detect one and set it by default. If the content type cannot be detected
 	// it is automatically set to "application/octet-stream" by the
-	// DetectContentType method
-	if r.Header().Get(contentTypeHeaderKey) == "" {
-		r.Header().Add(contentTypeHeaderKey, http.DetectContentType(body))
+	// DetectContentType method. Empty writes carry nothing to detect and must
+	// not give a content type to a genuinely empty body, for instance one
+	// announced with an explicit "Content-Length: 0".
+	if len(body) > 0 && r.Header().Get(contentTypeHeaderKey) == "" {
+		r.Header().Add(contentTypeHeaderKey, detectContentType(r.headers, r.requestPath, r.extensionContentType, body))
 
 	}
 
@@ -64,13 +96,83 @@ func (r *ProxyResponseWriter) Write(body []byte) (int, error) {
 
 }
 
+// detectContentType returns the content type matching the file extension
+// hint of header or the extension of requestPath when byExtension is set,
+// as with WithExtensionContentType, and sniffs it from body otherwise.
+func detectContentType(header http.Header, requestPath string, byExtension bool, body []byte) string {
+	if byExtension {
+		ext := header.Get(FileExtensionHintHeader)
+		if ext == "" {
+			ext = path.Ext(requestPath)
+
+		}
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+
+		}
+		if contentType := mime.TypeByExtension(ext); contentType != "" {
+			return contentType
+
+		}
+
+	}
+	return http.DetectContentType(body)
+
+}
+
 // WriteHeader sets a status code for the response. This method is used
 // for error responses.
 func (r *ProxyResponseWriter) WriteHeader(status int) {
+	r.writeHeaderCalls++
+	if r.debugLogger != nil {
+		if r.wroteBody {
+			r.debugLogger.Printf("WriteHeader(%d) called after Write on %s, a real server would ignore it", status, r.requestPath)
+
+		} else if r.writeHeaderCalls > 1 {
+			r.debugLogger.Printf("superfluous WriteHeader(%d) call on %s", status, r.requestPath)
+
+		}
+
+	}
 	r.status = status
 
 }
 
+// Status returns the status code written so far by the handler, or 0 if
+// neither WriteHeader nor Write has been called yet.
+func (r *ProxyResponseWriter) Status() int {
+	if r.status == defaultStatusCode {
+		return 0
+
+	}
+	return r.status
+
+}
+
+// WriteHeaderCalls returns how many times the handler called WriteHeader.
+func (r *ProxyResponseWriter) WriteHeaderCalls() int {
+	return r.writeHeaderCalls
+
+}
+
+// WroteBody reports whether the handler called Write.
+func (r *ProxyResponseWriter) WroteBody() bool {
+	return r.wroteBody
+
+}
+
+// Size returns the number of body bytes written so far by the handler.
+func (r *ProxyResponseWriter) Size() int {
+	return r.body.Len()
+
+}
+
+// Body returns the body written so far by the handler, as written.
+func (r *ProxyResponseWriter) Body() []byte {
+	return r.body.Bytes()
+
+}
+
 // GetProxyResponse converts the data passed to the response writer into
 // an events.ALBTargetGroupResponse object.
 // Returns a populated proxy response object. If the response is invalid, for example
@@ -80,12 +182,30 @@ func (r *ProxyResponseWriter) GetProxyResponse() (events.ALBTargetGroupResponse,
 		return events.ALBTargetGroupResponse{}, errors.New("Status code not set on response")
 	}
 
+	if r.extensionContentType {
+		r.headers.Del(FileExtensionHintHeader)
+
+	}
+	if r.contentSecurityPolicy != "" && isHTML(r.headers.Get(contentTypeHeaderKey)) && r.headers.Get(cspHeaderKey) == "" {
+		r.headers.Set(cspHeaderKey, r.contentSecurityPolicy)
+
+	}
+
 	var output string
 	isBase64 := false
 
 	bb := (&r.body).Bytes()
+	if r.prettyJSON && isJSON(r.headers.Get(contentTypeHeaderKey)) {
+		bb = indentJSON(bb)
+		// The handler announced the length of the body it wrote.
+		if r.headers.Get("Content-Length") != "" {
+			r.headers.Set("Content-Length", strconv.Itoa(len(bb)))
+
+		}
 
-	if utf8.Valid(bb) {
+	}
+
+	if utf8.Valid(bb) && !r.isBinaryContentType(r.headers.Get(contentTypeHeaderKey)) {
 		output = string(bb)
 
 	} else {
@@ -93,13 +213,148 @@ func (r *ProxyResponseWriter) GetProxyResponse() (events.ALBTargetGroupResponse,
 		isBase64 = true
 	}
 
-	return events.ALBTargetGroupResponse{
+	response := events.ALBTargetGroupResponse{
 		StatusCode:        r.status,
 		StatusDescription: description(r.status),
-		MultiValueHeaders: http.Header(r.headers),
 		Body:              output,
 		IsBase64Encoded:   isBase64,
-	}, nil
+	}
+	switch {
+	case r.dualHeaders:
+		// Set-Cookie headers cannot be joined; the single-value map keeps
+		// the last one, which is what a target group without multi-value
+		// headers would have kept.
+		response.Headers = make(map[string]string, len(r.headers))
+		for name, values := range r.headers {
+			if name == "Set-Cookie" {
+				response.Headers[name] = values[len(values)-1]
+				continue
+
+			}
+			response.Headers[name] = strings.Join(values, ", ")
+
+		}
+		response.MultiValueHeaders = http.Header(r.headers)
+
+	case r.singleValueHeaders:
+		response.Headers, response.MultiValueHeaders = r.splitHeaders()
+
+	default:
+		response.MultiValueHeaders = http.Header(r.headers)
+
+	}
+	if r.headerNames != nil {
+		response.Headers = renameHeaders(response.Headers, r.headerNames)
+		response.MultiValueHeaders = renameHeaders(response.MultiValueHeaders, r.headerNames)
+
+	}
+	return response, nil
+}
+
+// renameHeaders returns a copy of headers where the names found in names are
+// replaced by their value.
+func renameHeaders[V any](headers map[string]V, names map[string]string) map[string]V {
+	if headers == nil {
+		return nil
+
+	}
+	renamed := make(map[string]V, len(headers))
+	for name, value := range headers {
+		if original, ok := names[name]; ok {
+			name = original
+
+		}
+		renamed[name] = value
+
+	}
+	return renamed
+
+}
+
+// splitHeaders returns the single-value headers, where multiple values are
+// joined with a comma, and the headers forced to stay multi-value.
+func (r *ProxyResponseWriter) splitHeaders() (map[string]string, map[string][]string) {
+	single := make(map[string]string)
+	var multi map[string][]string
+	for name, values := range r.headers {
+		if r.isForcedMultiValue(name) {
+			if multi == nil {
+				multi = make(map[string][]string)
+
+			}
+			multi[name] = values
+			continue
+
+		}
+		single[name] = strings.Join(values, ", ")
+
+	}
+	return single, multi
+
+}
+
+func (r *ProxyResponseWriter) isForcedMultiValue(name string) bool {
+	for _, forced := range r.forceMultiValueHeaders {
+		if http.CanonicalHeaderKey(forced) == name {
+			return true
+
+		}
+
+	}
+	return false
+
+}
+
+// isBinaryContentType reports whether responses of contentType must always be
+// base64 encoded, even when their body happens to be valid UTF-8.
+func (r *ProxyResponseWriter) isBinaryContentType(contentType string) bool {
+	if len(r.binaryContentTypes) == 0 {
+		return false
+
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+
+	}
+	for _, binary := range r.binaryContentTypes {
+		if mediaType == binary {
+			return true
+
+		}
+
+	}
+	return false
+
+}
+
+// isJSON reports whether contentType is application/json or a +json type.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+
+}
+
+// isHTML reports whether contentType is text/html.
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/html"
+
+}
+
+// indentJSON returns body re-indented, or body untouched if it is not valid JSON.
+func indentJSON(body []byte) []byte {
+	var out bytes.Buffer
+	if err := json.Indent(&out, body, "", "  "); err != nil {
+		return body
+
+	}
+	return out.Bytes()
+
 }
 
 func description(statusCode int) string {
@@ -0,0 +1,241 @@
+package core
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestPrettyJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		contentType string
+		want        string
+	}{
+		{name: "enabled", opts: []Option{WithPrettyJSON()}, contentType: "application/json", want: "{\n  \"a\": 1\n}"},
+		{name: "disabled", contentType: "application/json", want: `{"a":1}`},
+		{name: "not JSON", opts: []Option{WithPrettyJSON()}, contentType: "text/plain", want: `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Header().Set("Content-Length", "7")
+				io.WriteString(w, `{"a":1}`)
+
+			})
+			req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/"}
+			resp, err := NewRequestAccessor(tt.opts...).Handle(context.Background(), req, h)
+			if err != nil || resp.Body != tt.want {
+				t.Errorf("got %q, %v, want %q", resp.Body, err, tt.want)
+
+			}
+			if got, want := resp.MultiValueHeaders["Content-Length"], []string{strconv.Itoa(len(tt.want))}; !reflect.DeepEqual(got, want) {
+				t.Errorf("got Content-Length %q, want %q", got, want)
+
+			}
+
+		})
+
+	}
+
+}
+
+func TestProxyResponseWriterStatusAndSize(t *testing.T) {
+	w := NewProxyResponseWriter()
+	if w.Status() != 0 || w.Size() != 0 {
+		t.Fatalf("before writing: got %d and %d, want 0 and 0", w.Status(), w.Size())
+
+	}
+	w.WriteHeader(http.StatusAccepted)
+	io.WriteString(w, "hello")
+	io.WriteString(w, " world")
+	if w.Status() != http.StatusAccepted || w.Size() != 11 {
+		t.Errorf("got %d and %d, want 202 and 11", w.Status(), w.Size())
+
+	}
+
+	w = NewProxyResponseWriter()
+	io.WriteString(w, "hello")
+	if w.Status() != http.StatusOK {
+		t.Errorf("implicit status: got %d, want 200", w.Status())
+
+	}
+
+}
+
+func TestForceMultiValueHeaders(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Set-Cookie", "a=1")
+		w.Header().Add("Set-Cookie", "b=2")
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Origin")
+		w.WriteHeader(http.StatusOK)
+
+	})
+	r := NewRequestAccessor(WithSingleValueHeaders(), WithForceMultiValueHeaders([]string{"set-cookie"}))
+	req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/"}
+	resp, err := r.Handle(context.Background(), req, h)
+	if err != nil {
+		t.Fatal(err)
+
+	}
+	if got := resp.MultiValueHeaders["Set-Cookie"]; len(got) != 2 || got[0] != "a=1" || got[1] != "b=2" {
+		t.Errorf("multi-value Set-Cookie: got %q, want [a=1 b=2]", got)
+
+	}
+	if _, ok := resp.Headers["Set-Cookie"]; ok {
+		t.Errorf("single-value headers hold Set-Cookie: %q", resp.Headers)
+
+	}
+	if got := resp.Headers["Vary"]; got != "Accept, Origin" {
+		t.Errorf("single-value Vary: got %q, want \"Accept, Origin\"", got)
+
+	}
+	if _, ok := resp.MultiValueHeaders["Vary"]; ok {
+		t.Errorf("multi-value headers hold Vary: %q", resp.MultiValueHeaders)
+
+	}
+
+}
+
+func TestExtensionContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		hint string
+		want string
+	}{
+		{name: "path extension", path: "/static/app.js", want: "text/javascript"},
+		{name: "hint", path: "/static/app", hint: "svg", want: "image/svg+xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if tt.hint != "" {
+					w.Header().Set(FileExtensionHintHeader, tt.hint)
+
+				}
+				io.WriteString(w, "console.log(1)")
+
+			})
+			req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: tt.path}
+			resp, err := NewRequestAccessor(WithExtensionContentType()).Handle(context.Background(), req, h)
+			if err != nil {
+				t.Fatal(err)
+
+			}
+			header := http.Header(resp.MultiValueHeaders)
+			if mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type")); mediaType != tt.want {
+				t.Errorf("got Content-Type %q, want %s", header.Get("Content-Type"), tt.want)
+
+			}
+			if _, ok := header[FileExtensionHintHeader]; ok {
+				t.Errorf("hint header left in the response")
+
+			}
+
+		})
+
+	}
+
+}
+
+func TestWriteHeaderAfterWriteWarning(t *testing.T) {
+	var lines lineLogger
+	var writer *ProxyResponseWriter
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writer = w.(*ProxyResponseWriter)
+		io.WriteString(w, "partial")
+		w.WriteHeader(http.StatusInternalServerError)
+
+	})
+	req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/items"}
+	if _, err := NewRequestAccessor(WithDebug(), WithLogger(&lines)).Handle(context.Background(), req, h); err != nil {
+		t.Fatal(err)
+
+	}
+	if writer.WriteHeaderCalls() != 1 || !writer.WroteBody() {
+		t.Errorf("got %d WriteHeader calls and WroteBody %v, want 1 and true", writer.WriteHeaderCalls(), writer.WroteBody())
+
+	}
+	want := "WriteHeader(500) called after Write on /items, a real server would ignore it"
+	if len(lines) != 1 || lines[0] != want {
+		t.Errorf("got log lines %q, want %q", lines, want)
+
+	}
+
+}
+
+func TestExplicitZeroContentLength(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusOK)
+		w.Write(nil)
+
+	})
+	req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/"}
+	resp, err := NewRequestAccessor().Handle(context.Background(), req, h)
+	if err != nil || resp.StatusCode != http.StatusOK || resp.Body != "" || resp.IsBase64Encoded {
+		t.Fatalf("got %d %q, %v, want an empty 200", resp.StatusCode, resp.Body, err)
+
+	}
+	header := http.Header(resp.MultiValueHeaders)
+	if got := header.Get("Content-Length"); got != "0" {
+		t.Errorf("got Content-Length %q, want 0", got)
+
+	}
+	if _, ok := header["Content-Type"]; ok {
+		t.Errorf("got Content-Type %q, want none", header.Get("Content-Type"))
+
+	}
+
+}
+
+func TestContentSecurityPolicy(t *testing.T) {
+	const policy = "default-src 'self'"
+	tests := []struct {
+		name        string
+		contentType string
+		handlerCSP  string
+		want        string
+	}{
+		{name: "HTML", contentType: "text/html; charset=utf-8", want: policy},
+		{name: "JSON", contentType: "application/json"},
+		{name: "handler policy", contentType: "text/html", handlerCSP: "default-src 'none'", want: "default-src 'none'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				if tt.handlerCSP != "" {
+					w.Header().Set("Content-Security-Policy", tt.handlerCSP)
+
+				}
+				io.WriteString(w, "{}")
+
+			})
+			req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/"}
+			resp, err := NewRequestAccessor(WithContentSecurityPolicy(policy)).Handle(context.Background(), req, h)
+			if err != nil {
+				t.Fatal(err)
+
+			}
+			if got := resp.MultiValueHeaders["Content-Security-Policy"]; strings.Join(got, ", ") != tt.want {
+				t.Errorf("got Content-Security-Policy %q, want %q", got, tt.want)
+
+			}
+
+		})
+
+	}
+
+}
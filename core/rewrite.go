@@ -0,0 +1,37 @@
+package core
+
+import (
+	"regexp"
+)
+
+// RewriteRule replaces the path of the requests matching Pattern with
+// Replacement, in which $1 or ${name} stand for the submatches of Pattern as
+// in regexp.Regexp.Expand. Both work on the percent-encoded path, after the
+// base path is stripped.
+type RewriteRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// Rewrite returns the RewriteRule of a pattern, such as
+// Rewrite(`^/legacy/items/(\d+)$`, "/v2/items/$1"). It panics if pattern does
+// not compile.
+func Rewrite(pattern string, replacement string) RewriteRule {
+	return RewriteRule{Pattern: regexp.MustCompile(pattern), Replacement: replacement}
+
+}
+
+// rewritePath applies the first of the rewrite rules matching path.
+func (r *settings) rewritePath(path string) string {
+	for _, rule := range r.rewriteRules {
+		match := rule.Pattern.FindStringSubmatchIndex(path)
+		if match == nil {
+			continue
+
+		}
+		return string(rule.Pattern.ExpandString(nil, rule.Replacement, path, match))
+
+	}
+	return path
+
+}
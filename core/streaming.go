@@ -0,0 +1,250 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// StreamingResponseWriter implements http.ResponseWriter and http.Flusher on
+// top of a pipe read by the Lambda response streaming API. The status code
+// and headers are sent with the first call to WriteHeader, Write or Flush;
+// the body is sent as it is written instead of being buffered.
+type StreamingResponseWriter struct {
+	headers http.Header
+	pipe    *io.PipeWriter
+	status  int
+	size    int
+
+	requestPath           string
+	extensionContentType  bool
+	contentSecurityPolicy string
+
+	startOnce sync.Once
+	started   chan struct{}
+	response  *events.LambdaFunctionURLStreamingResponse
+}
+
+// newStreamingResponseWriter returns a StreamingResponseWriter for a request
+// to path, configured with the response related options of the Accessor.
+func (r *settings) newStreamingResponseWriter(pipe *io.PipeWriter, body io.Reader, path string) *StreamingResponseWriter {
+	return &StreamingResponseWriter{
+		headers:               make(http.Header),
+		pipe:                  pipe,
+		requestPath:           path,
+		extensionContentType:  r.extensionContentType,
+		contentSecurityPolicy: r.contentSecurityPolicy,
+		started:               make(chan struct{}),
+		response:              &events.LambdaFunctionURLStreamingResponse{Body: body},
+	}
+
+}
+
+// Header implementation from the http.ResponseWriter interface. Changes made
+// after the response started are ignored.
+func (w *StreamingResponseWriter) Header() http.Header {
+	return w.headers
+
+}
+
+// WriteHeader sends the status code and the headers of the response.
+func (w *StreamingResponseWriter) WriteHeader(status int) {
+	w.start(status)
+
+}
+
+// Write streams body to the client, sending the status code and headers first
+// if needed. It blocks until the client read body.
+func (w *StreamingResponseWriter) Write(body []byte) (int, error) {
+	if len(body) > 0 && w.headers.Get(contentTypeHeaderKey) == "" {
+		select {
+		case <-w.started:
+
+		default:
+			w.headers.Set(contentTypeHeaderKey, detectContentType(w.headers, w.requestPath, w.extensionContentType, body))
+
+		}
+
+	}
+	w.start(http.StatusOK)
+	n, err := w.pipe.Write(body)
+	w.size += n
+	return n, err
+
+}
+
+// Status returns the status code sent so far, or 0 if the response has not
+// started yet.
+func (w *StreamingResponseWriter) Status() int {
+	return w.status
+
+}
+
+// Size returns the number of body bytes streamed so far.
+func (w *StreamingResponseWriter) Size() int {
+	return w.size
+
+}
+
+// Flush implementation from the http.Flusher interface. Writes are never
+// buffered, so it only sends the status code and headers if needed.
+func (w *StreamingResponseWriter) Flush() {
+	w.start(http.StatusOK)
+
+}
+
+// start builds the streaming response from the status code and headers once.
+func (w *StreamingResponseWriter) start(status int) {
+	w.startOnce.Do(func() {
+		if w.extensionContentType {
+			w.headers.Del(FileExtensionHintHeader)
+
+		}
+		if w.contentSecurityPolicy != "" && isHTML(w.headers.Get(contentTypeHeaderKey)) && w.headers.Get(cspHeaderKey) == "" {
+			w.headers.Set(cspHeaderKey, w.contentSecurityPolicy)
+
+		}
+		w.status = status
+		w.response.StatusCode = status
+		w.response.Headers = make(map[string]string, len(w.headers))
+		for name, values := range w.headers {
+			if name == "Set-Cookie" {
+				w.response.Cookies = append(w.response.Cookies, values...)
+				continue
+
+			}
+			w.response.Headers[name] = strings.Join(values, ",")
+
+		}
+		close(w.started)
+
+	})
+
+}
+
+// Stream converts an event and context into an http.Request and serves it
+// with h in the background. It returns as soon as h sends the status code and
+// headers, with a response whose body streams what h writes next, for
+// functions invoked through a Function URL in RESPONSE_STREAM mode.
+// Panics in h are always recovered, since they happen outside of the
+// invocation goroutine, and abort the body stream.
+// With WithDeadlineBuffer, h gets a 504 if it has not started responding by
+// the earlier deadline; once it has, the deadline only ends its context.
+// The options needing the whole response, WithPrettyJSON and
+// WithDefaultOptions, and those laying out ALB response headers,
+// WithSingleValueHeaders, WithDualHeaders, WithForceMultiValueHeaders and
+// WithPreservedHeaderCase, do not apply to streamed responses.
+func (r *Accessor[E, R]) Stream(ctx context.Context, req E, h http.Handler) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return r.stream(ctx, newEvent(req), h)
+
+}
+
+func (r *settings) stream(ctx context.Context, req event, h http.Handler) (*events.LambdaFunctionURLStreamingResponse, error) {
+	resp, release, ok := r.admit(ctx, req)
+	if !ok {
+		return bufferedStreamingResponse(resp), nil
+
+	}
+
+	start := time.Now()
+	ctx, cancel := r.withDeadlineBuffer(ctx)
+	httpRequest, err := r.eventToRequestWithContext(ctx, req)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		cancel()
+		release()
+		resp := r.errorResponse(httpErr)
+		r.observe(resp.StatusCode, start, eventBodySize(req), len(resp.Body))
+		return bufferedStreamingResponse(resp), nil
+
+	}
+	if err != nil {
+		cancel()
+		release()
+		r.observe(http.StatusGatewayTimeout, start, eventBodySize(req), 0)
+		return nil, NewLoggedError("Could not convert proxy event to request: %v", err)
+
+	}
+
+	h = r.withHeaderCase(h, req)
+	if r.bodyValidator != nil {
+		h = r.validateBody(h)
+
+	}
+	if r.rateLimiter != nil {
+		h = r.rateLimit(h)
+
+	}
+
+	method, path, requestBytes := httpRequest.Method, httpRequest.URL.Path, httpRequest.ContentLength
+	body, pipe := io.Pipe()
+	w := r.newStreamingResponseWriter(pipe, body, path)
+	var timedOut atomic.Bool
+	go func() {
+		defer release()
+		defer cancel()
+		defer removeMultipartForms(httpRequest)
+		var err error
+		defer func() {
+			if v := recover(); v != nil {
+				err = &PanicError{Value: v, Stack: debug.Stack()}
+				r.logRequestError(method, path, http.StatusInternalServerError, err)
+				w.start(http.StatusInternalServerError)
+
+			}
+			if !timedOut.Load() {
+				entry := newAccessLogEntry(httpRequest, w, time.Since(start))
+				r.logAccess(entry)
+				r.emitEMF(entry)
+				r.observe(w.Status(), start, requestBytes, w.Size())
+
+			}
+			pipe.CloseWithError(err)
+
+		}()
+		h.ServeHTTP(w, httpRequest)
+		w.start(http.StatusOK)
+
+	}()
+
+	select {
+	case <-w.started:
+		return w.response, nil
+
+	case <-ctx.Done():
+		// h keeps running until it gives up, but its writes now fail.
+		timedOut.Store(true)
+		body.CloseWithError(ErrDeadlineExceeded)
+		r.logRequestError(method, path, http.StatusGatewayTimeout, ErrDeadlineExceeded)
+		r.observe(http.StatusGatewayTimeout, start, requestBytes, 0)
+		return bufferedStreamingResponse(TimeoutResponse()), nil
+
+	}
+
+}
+
+// bufferedStreamingResponse returns resp as a streaming response.
+func bufferedStreamingResponse(resp events.ALBTargetGroupResponse) *events.LambdaFunctionURLStreamingResponse {
+	out := ALBToFunctionURLResponse(resp)
+	var body io.Reader = strings.NewReader(out.Body)
+	if out.IsBase64Encoded {
+		body = base64.NewDecoder(base64.StdEncoding, body)
+
+	}
+	return &events.LambdaFunctionURLStreamingResponse{
+		StatusCode: out.StatusCode,
+		Headers:    out.Headers,
+		Body:       body,
+		Cookies:    out.Cookies,
+	}
+
+}
@@ -13,6 +13,11 @@ func TimeoutResponse() events.ALBTargetGroupResponse {
 	return events.ALBTargetGroupResponse{StatusCode: http.StatusGatewayTimeout, StatusDescription: strconv.Itoa(http.StatusGatewayTimeout)}
 }
 
+// statusResponse returns an empty response with the given status code
+func statusResponse(status int) events.ALBTargetGroupResponse {
+	return events.ALBTargetGroupResponse{StatusCode: status, StatusDescription: strconv.Itoa(status)}
+}
+
 // NewLoggedError generates a new error and logs it to stdout
 func NewLoggedError(format string, a ...interface{}) error {
 	err := fmt.Errorf(format, a...)
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// FieldError describes why a field of a request body is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// validationErrorBody is the JSON body of the responses listing field errors,
+// such as the 422 response returned when the body validator reports errors.
+type validationErrorBody struct {
+	Errors []FieldError `json:"errors"`
+}
+
+// validateBody wraps next so that request bodies with a validated content
+// type are checked before routing. Invalid bodies get a 422 response listing
+// the field errors and never reach next.
+func (r *settings) validateBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !r.isValidatedContentType(req.Header.Get(contentTypeHeaderKey)) {
+			next.ServeHTTP(w, req)
+			return
+
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		fieldErrors := r.bodyValidator(body)
+		if len(fieldErrors) == 0 {
+			next.ServeHTTP(w, req)
+			return
+
+		}
+
+		w.Header().Set(contentTypeHeaderKey, "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(validationErrorBody{Errors: fieldErrors})
+
+	})
+
+}
+
+func (r *settings) isValidatedContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+
+	}
+	for _, validated := range r.validatedContentTypes {
+		if mediaType == validated {
+			return true
+
+		}
+
+	}
+	return false
+
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestBodyValidator(t *testing.T) {
+	validate := func(body []byte) []FieldError {
+		var item struct {
+			Name  string `json:"name"`
+			Price int    `json:"price"`
+		}
+		if err := json.Unmarshal(body, &item); err != nil {
+			return []FieldError{{Field: "", Message: "invalid JSON"}}
+
+		}
+		var errs []FieldError
+		if item.Name == "" {
+			errs = append(errs, FieldError{Field: "name", Message: "required"})
+
+		}
+		if item.Price <= 0 {
+			errs = append(errs, FieldError{Field: "price", Message: "must be positive"})
+
+		}
+		return errs
+
+	}
+	called := false
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusCreated)
+
+	})
+	r := NewRequestAccessor(WithBodyValidator(validate))
+	req := events.ALBTargetGroupRequest{
+		HTTPMethod: http.MethodPost,
+		Path:       "/items",
+		Headers:    map[string]string{"content-type": "application/json"},
+		Body:       `{"price":0}`,
+	}
+
+	resp, err := r.Handle(context.Background(), req, h)
+	if err != nil || resp.StatusCode != http.StatusUnprocessableEntity || called {
+		t.Fatalf("invalid body: got %d, %v, handler called %v, want 422 without calling the handler", resp.StatusCode, err, called)
+
+	}
+	var body validationErrorBody
+	if err := json.Unmarshal([]byte(resp.Body), &body); err != nil {
+		t.Fatalf("invalid error body %q: %v", resp.Body, err)
+
+	}
+	want := []FieldError{{Field: "name", Message: "required"}, {Field: "price", Message: "must be positive"}}
+	if !reflect.DeepEqual(body.Errors, want) {
+		t.Errorf("got errors %v, want %v", body.Errors, want)
+
+	}
+
+	req.Body = `{"name":"pen","price":2}`
+	resp, err = r.Handle(context.Background(), req, h)
+	if err != nil || resp.StatusCode != http.StatusCreated || !called {
+		t.Errorf("valid body: got %d, %v, want 201 from the handler", resp.StatusCode, err)
+
+	}
+
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// Stage is the name of the API Gateway stage that received a request.
+type Stage string
+
+// StageVariables are the variables of the API Gateway stage that received a request.
+type StageVariables map[string]string
+
+// PathParameters are the parameters API Gateway extracted from the request
+// path with the route of the API.
+type PathParameters map[string]string
+
+// FeatureFlags are the flags collected from the headers matching the prefix
+// set with WithFeatureFlagHeaderPrefix.
+type FeatureFlags map[string]string
+
+// requestValues are the request values attached to a context by an Accessor,
+// indexed by their type.
+type requestValues map[reflect.Type]interface{}
+
+// downstreamClient is the client set with WithDownstreamClient.
+type downstreamClient struct {
+	*http.Client
+}
+
+// rawEvent is the event a request was converted from, whatever its type.
+type rawEvent struct {
+	event interface{}
+}
+
+// FromContext retrieve the request value of type T from context.Context, such
+// as *lambdacontext.LambdaContext, events.ALBTargetGroupRequestContext,
+// events.APIGatewayProxyRequestContext, Stage, PathParameters, Principal or
+// the event the request was converted from, like events.ALBTargetGroupRequest.
+// Returns false when the request has no value of type T.
+func FromContext[T any](ctx context.Context) (T, bool) {
+	values, _ := ctx.Value(ctxKey{}).(requestValues)
+	v, ok := values[reflect.TypeFor[T]()].(T)
+	return v, ok
+
+}
+
+// WithValue returns a copy of ctx carrying v as the request value of type T,
+// next to the values attached by the Accessor, so that middlewares can
+// attach their own values for FromContext and CopyRequestContext.
+func WithValue[T any](ctx context.Context, v T) context.Context {
+	values, _ := ctx.Value(ctxKey{}).(requestValues)
+	copied := make(requestValues, len(values)+1)
+	for t, value := range values {
+		copied[t] = value
+
+	}
+	setValue(copied, v)
+	return context.WithValue(ctx, ctxKey{}, copied)
+
+}
+
+// setValue sets v as the value of type T of values.
+func setValue[T any](values requestValues, v T) {
+	values[reflect.TypeFor[T]()] = v
+
+}
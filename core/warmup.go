@@ -0,0 +1,47 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// warmupProbe holds the fields of the payloads sent by function warmers.
+type warmupProbe struct {
+	Source     string          `json:"source"`
+	DetailType string          `json:"detail-type"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+// isWarmupPayload reports whether payload is a warm-up ping: the payload of
+// serverless-plugin-warmup or a scheduled event without detail.
+func isWarmupPayload(payload json.RawMessage) bool {
+	var probe warmupProbe
+	if err := json.Unmarshal(payload, &probe); err != nil {
+		return false
+
+	}
+	switch {
+	case probe.Source == "serverless-plugin-warmup":
+		return true
+
+	case probe.Source == "aws.events" && probe.DetailType == "Scheduled Event":
+		detail := bytes.TrimSpace(probe.Detail)
+		return len(detail) == 0 || bytes.Equal(detail, []byte("{}")) || bytes.Equal(detail, []byte("null"))
+
+	}
+	return false
+
+}
+
+// isWarmupRequest reports whether req carries the warm-up header set with WithWarmup.
+func (r *settings) isWarmupRequest(req event) bool {
+	return r.warmup && r.warmupHeader != "" && req.header().Get(r.warmupHeader) != ""
+}
+
+// warmupResponse is the answer to warm-up pings.
+func warmupResponse() events.ALBTargetGroupResponse {
+	return statusResponse(http.StatusOK)
+}
@@ -7,52 +7,79 @@ package echoadapter
 
 import (
 	"context"
-	"net/http"
+	"encoding/json"
+	"fmt"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/labstack/echo"
 	"github.com/toff63/lambda-http-adapter/core"
 )
 
+func init() {
+	core.RegisterAdapter("echo", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		e, ok := app.(*echo.Echo)
+		if !ok {
+			return nil, fmt.Errorf("echoadapter: %T is not an *echo.Echo", app)
+
+		}
+		return New(e, opts...), nil
+
+	})
+
+}
+
 // EchoLambda makes it easy to send API Gateway proxy events to a echo.Echo.
 // The library transforms the proxy event into an HTTP request and then
 // creates a proxy response object from the http.ResponseWriter
-type EchoLambda struct {
-	core.RequestAccessor
+type EchoLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the EchoLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
 
 	Echo *echo.Echo
 }
 
 // New creates a new instance of the EchoLambda object.
 // Receives an initialized *echo.Echo object - normally created with echo.New().
+// Options configure the underlying core.RequestAccessor.
 // It returns the initialized instance of the EchoLambda object.
-func New(e *echo.Echo) *EchoLambda {
-	return &EchoLambda{Echo: e}
+func New(e *echo.Echo, opts ...core.Option) *EchoLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](e, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](e *echo.Echo, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Echo: e}
+	l.Configure(opts...)
+	return l
 
 }
 
 // ProxyWithContext receives context and an API Gateway proxy event,
 // transforms them into an http.Request object, and sends it to the echo.Echo for routing.
 // It returns a proxy response object generated from the http.ResponseWriter.
-func (e *EchoLambda) ProxyWithContext(ctx context.Context, req events.ALBTargetGroupRequest) (events.ALBTargetGroupResponse, error) {
-	echoRequest, err := e.EventToRequestWithContext(ctx, req)
-	return e.proxyInternal(echoRequest, err)
+func (e *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return e.Handle(ctx, req, e.Echo)
 }
 
-func (e *EchoLambda) proxyInternal(req *http.Request, err error) (events.ALBTargetGroupResponse, error) {
-	if err != nil {
-		return core.TimeoutResponse(), core.NewLoggedError("Could not convert proxy event to request: %v", err)
-	}
-
-	respWriter := core.NewProxyResponseWriter()
-	e.Echo.ServeHTTP(http.ResponseWriter(respWriter), req)
-
-	proxyResponse, err := respWriter.GetProxyResponse()
-	if err != nil {
-		return core.TimeoutResponse(), core.NewLoggedError("Error while generating proxy response: %v", err)
-
-	}
-
-	return proxyResponse, nil
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (e *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return e.Stream(ctx, req, e.Echo)
+}
 
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (e *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return e.HandleRaw(ctx, payload, e.Echo)
 }
@@ -0,0 +1,86 @@
+// Package echoadapter add Echo v4 support for the library.
+// It mirrors the parent echoadapter package, which targets the Echo import
+// path without a major version, for applications importing
+// github.com/labstack/echo/v4.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to the echo.Echo
+package echoadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/labstack/echo/v4"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+func init() {
+	core.RegisterAdapter("echo/v4", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		e, ok := app.(*echo.Echo)
+		if !ok {
+			return nil, fmt.Errorf("echoadapter: %T is not an *echo.Echo", app)
+
+		}
+		return New(e, opts...), nil
+
+	})
+
+}
+
+// EchoLambda makes it easy to send API Gateway proxy events to a echo.Echo.
+// The library transforms the proxy event into an HTTP request and then
+// creates a proxy response object from the http.ResponseWriter
+type EchoLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the EchoLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Echo *echo.Echo
+}
+
+// New creates a new instance of the EchoLambda object.
+// Receives an initialized *echo.Echo object - normally created with echo.New().
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the EchoLambda object.
+func New(e *echo.Echo, opts ...core.Option) *EchoLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](e, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](e *echo.Echo, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Echo: e}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an API Gateway proxy event,
+// transforms them into an http.Request object, and sends it to the echo.Echo for routing.
+// It returns a proxy response object generated from the http.ResponseWriter.
+func (e *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return e.Handle(ctx, req, e.Echo)
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (e *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return e.Stream(ctx, req, e.Echo)
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (e *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return e.HandleRaw(ctx, payload, e.Echo)
+}
@@ -0,0 +1,94 @@
+// Package eventbridgeadapter adds EventBridge support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send EventBridge events, including
+// scheduled ones, to an http.Handler as synthetic HTTP requests.
+package eventbridgeadapter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+// EventBridgeLambda makes it easy to send EventBridge events to an http.Handler.
+// Each event becomes a POST request whose JSON body is the detail of the event
+// and whose path is the source followed by the detail type, such as
+// /aws.events/Scheduled%20Event. The source, detail type and ID of the event
+// are sent in the X-Eventbridge-Source, X-Eventbridge-Detail-Type and
+// X-Eventbridge-Id headers.
+type EventBridgeLambda struct {
+	core.RequestAccessor
+
+	Handler http.Handler
+
+	// Path replaces the default derivation of the request path when set.
+	Path func(events.CloudWatchEvent) string
+}
+
+// New creates a new instance of the EventBridgeLambda object.
+// Receives the http.Handler events are sent to.
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the EventBridgeLambda object.
+func New(handler http.Handler, opts ...core.Option) *EventBridgeLambda {
+	l := &EventBridgeLambda{Handler: handler}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an EventBridge event and sends it to
+// the http.Handler.
+// It returns an error if the event could not be converted or was answered
+// with a status code of 400 or more, so that Lambda retries the event.
+func (e *EventBridgeLambda) ProxyWithContext(ctx context.Context, ev events.CloudWatchEvent) error {
+	ctx = context.WithValue(ctx, ctxKey{}, ev)
+	resp, err := e.Handle(ctx, e.toALBRequest(ev), e.Handler)
+	if err != nil {
+		return err
+
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return core.NewLoggedError("Event %s was answered with status %d", ev.ID, resp.StatusCode)
+
+	}
+	return nil
+}
+
+// toALBRequest converts an event into the ALB event of its request.
+func (e *EventBridgeLambda) toALBRequest(ev events.CloudWatchEvent) events.ALBTargetGroupRequest {
+	path := e.Path
+	if path == nil {
+		path = defaultPath
+
+	}
+	return events.ALBTargetGroupRequest{
+		HTTPMethod: http.MethodPost,
+		Path:       path(ev),
+		Headers: map[string]string{
+			"Content-Type":              "application/json",
+			"X-Eventbridge-Source":      ev.Source,
+			"X-Eventbridge-Detail-Type": ev.DetailType,
+			"X-Eventbridge-Id":          ev.ID,
+		},
+		Body: string(ev.Detail),
+	}
+
+}
+
+// defaultPath returns the source of the event followed by its detail type.
+func defaultPath(ev events.CloudWatchEvent) string {
+	return "/" + url.PathEscape(ev.Source) + "/" + url.PathEscape(ev.DetailType)
+
+}
+
+type ctxKey struct{}
+
+// GetEvent retrieve the EventBridge event a request was built from, for use in
+// handlers and middlewares.
+func GetEvent(r *http.Request) (events.CloudWatchEvent, bool) {
+	v, ok := r.Context().Value(ctxKey{}).(events.CloudWatchEvent)
+	return v, ok
+}
@@ -0,0 +1,140 @@
+// Package fasthttpadapter adds fasthttp support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to a
+// fasthttp.RequestHandler. Events are converted straight into a
+// fasthttp.RequestCtx, without going through net/http types, and served with
+// core.Accessor.HandleDirect.
+package fasthttpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/toff63/lambda-http-adapter/core"
+	"github.com/valyala/fasthttp"
+)
+
+func init() {
+	core.RegisterAdapter("fasthttp", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		switch handler := app.(type) {
+		case fasthttp.RequestHandler:
+			return New(handler, opts...), nil
+
+		case func(*fasthttp.RequestCtx):
+			return New(handler, opts...), nil
+
+		}
+		return nil, fmt.Errorf("fasthttpadapter: %T is not a fasthttp.RequestHandler", app)
+
+	})
+
+}
+
+// contextKey is the user value key of the request context.
+const contextKey = "lambda-http-adapter.context"
+
+// FastHTTPLambda makes it easy to send ALB events to a fasthttp.RequestHandler.
+// The library transforms the ALB event into a fasthttp.RequestCtx and then
+// creates an ALB response object from the fasthttp response.
+type FastHTTPLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the FastHTTPLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Handler fasthttp.RequestHandler
+}
+
+// New creates a new instance of the FastHTTPLambda object.
+// Receives the fasthttp.RequestHandler requests are sent to.
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the FastHTTPLambda object.
+func New(handler fasthttp.RequestHandler, opts ...core.Option) *FastHTTPLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](handler, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](handler fasthttp.RequestHandler, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Handler: handler}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into a fasthttp.RequestCtx, and sends it to the fasthttp.RequestHandler.
+// It returns an ALB response object generated from the fasthttp response.
+func (f *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return f.HandleDirect(ctx, req, NewHandler(f.Handler))
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns a streaming response. Pass it to lambda.Start
+// for functions behind a Function URL in RESPONSE_STREAM mode. fasthttp
+// handlers write whole responses, so the body is sent once the handler has
+// returned; see core.Accessor.StreamDirect.
+func (f *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return f.StreamDirect(ctx, req, NewHandler(f.Handler))
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (f *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return f.HandleRawDirect(ctx, payload, NewHandler(f.Handler))
+}
+
+// NewHandler returns the core.DirectHandler through which an Accessor serves
+// its events with h: it builds the fasthttp.RequestCtx of h from the
+// converted event and writes the fasthttp response into the response of the
+// event.
+func NewHandler(h fasthttp.RequestHandler) core.DirectHandler {
+	return func(req *core.DirectRequest, w *core.ProxyResponseWriter) {
+		var request fasthttp.Request
+		request.Header.SetMethod(req.Method)
+		request.Header.SetHost(req.Host)
+		for name, values := range req.Header {
+			for _, v := range values {
+				request.Header.Add(name, v)
+
+			}
+
+		}
+		// The URI takes its host from the header when it is parsed, so it
+		// is set last. Keep encoded segments such as %2F in RequestURI and
+		// PathOriginal.
+		request.SetRequestURI(req.RequestURI())
+		request.URI().DisablePathNormalizing = true
+		request.SetBody(req.Body)
+
+		var fctx fasthttp.RequestCtx
+		fctx.Init(&request, &net.TCPAddr{IP: net.ParseIP(req.ClientIP)}, nil)
+		fctx.SetUserValue(contextKey, req.Context)
+		h(&fctx)
+
+		fctx.Response.Header.VisitAll(func(name []byte, value []byte) {
+			w.Header().Add(string(name), string(value))
+
+		})
+		w.WriteHeader(fctx.Response.StatusCode())
+		w.Write(fctx.Response.Body())
+
+	}
+
+}
+
+// GetContext returns the context of the converted request, which carries the
+// ALB and Lambda runtime contexts, from the fasthttp.RequestCtx of a handler.
+// Read them with core.GetALBContextFromContext and core.GetRuntimeContextFromContext.
+func GetContext(fctx *fasthttp.RequestCtx) (context.Context, bool) {
+	ctx, ok := fctx.UserValue(contextKey).(context.Context)
+	return ctx, ok
+}
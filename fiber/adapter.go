@@ -0,0 +1,94 @@
+// Package fiberadapter adds Fiber support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to the fiber.App
+package fiberadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gofiber/fiber/v2"
+	"github.com/toff63/lambda-http-adapter/core"
+	fasthttpadapter "github.com/toff63/lambda-http-adapter/fasthttp"
+	"github.com/valyala/fasthttp"
+)
+
+func init() {
+	core.RegisterAdapter("fiber", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		a, ok := app.(*fiber.App)
+		if !ok {
+			return nil, fmt.Errorf("fiberadapter: %T is not a *fiber.App", app)
+
+		}
+		return New(a, opts...), nil
+
+	})
+
+}
+
+// FiberLambda makes it easy to send ALB events to a fiber.App.
+// The library transforms the ALB event straight into the fasthttp.RequestCtx
+// served by the fiber.App, as fasthttpadapter.NewHandler does, and creates
+// an ALB response object from the fasthttp response.
+type FiberLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the FiberLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	App *fiber.App
+}
+
+// New creates a new instance of the FiberLambda object.
+// Receives an initialized *fiber.App object - normally created with fiber.New().
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the FiberLambda object.
+func New(app *fiber.App, opts ...core.Option) *FiberLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](app, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](app *fiber.App, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{App: app}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into a fasthttp.RequestCtx, and sends it to the fiber.App for routing.
+// It returns an ALB response object generated from the fasthttp response.
+func (f *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return f.HandleDirect(ctx, req, fasthttpadapter.NewHandler(f.App.Handler()))
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns a streaming response. Pass it to lambda.Start
+// for functions behind a Function URL in RESPONSE_STREAM mode. fasthttp
+// handlers write whole responses, so the body is sent once the handler has
+// returned; see core.Accessor.StreamDirect.
+func (f *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return f.StreamDirect(ctx, req, fasthttpadapter.NewHandler(f.App.Handler()))
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (f *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return f.HandleRawDirect(ctx, payload, fasthttpadapter.NewHandler(f.App.Handler()))
+}
+
+// GetContext returns the context of the converted request, which carries the
+// ALB and Lambda runtime contexts, from the fasthttp.RequestCtx of a fiber
+// handler (c.Context()). Read them with core.GetALBContextFromContext and
+// core.GetRuntimeContextFromContext.
+func GetContext(fctx *fasthttp.RequestCtx) (context.Context, bool) {
+	return fasthttpadapter.GetContext(fctx)
+}
@@ -0,0 +1,83 @@
+// Package ginadapter adds Gin support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to the gin.Engine
+package ginadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+func init() {
+	core.RegisterAdapter("gin", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		g, ok := app.(*gin.Engine)
+		if !ok {
+			return nil, fmt.Errorf("ginadapter: %T is not a *gin.Engine", app)
+
+		}
+		return New(g, opts...), nil
+
+	})
+
+}
+
+// GinLambda makes it easy to send ALB events to a gin.Engine.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter
+type GinLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the GinLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Gin *gin.Engine
+}
+
+// New creates a new instance of the GinLambda object.
+// Receives an initialized *gin.Engine object - normally created with gin.Default().
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the GinLambda object.
+func New(g *gin.Engine, opts ...core.Option) *GinLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](g, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](g *gin.Engine, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Gin: g}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the gin.Engine for routing.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (g *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return g.Handle(ctx, req, g.Gin)
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (g *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return g.Stream(ctx, req, g.Gin)
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (g *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return g.HandleRaw(ctx, payload, g.Gin)
+}
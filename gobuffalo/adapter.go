@@ -0,0 +1,96 @@
+// Package gobuffaloadapter adds Buffalo support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to the buffalo.App
+package gobuffaloadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/gobuffalo/buffalo"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+func init() {
+	core.RegisterAdapter("gobuffalo", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		a, ok := app.(*buffalo.App)
+		if !ok {
+			return nil, fmt.Errorf("gobuffaloadapter: %T is not a *buffalo.App", app)
+
+		}
+		return New(a, opts...), nil
+
+	})
+
+}
+
+// BuffaloLambda makes it easy to send ALB events to a buffalo.App.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter
+type BuffaloLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the BuffaloLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	App *buffalo.App
+}
+
+// New creates a new instance of the BuffaloLambda object.
+// Receives an initialized *buffalo.App object - normally created with buffalo.New().
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the BuffaloLambda object.
+func New(app *buffalo.App, opts ...core.Option) *BuffaloLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](app, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](app *buffalo.App, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{App: app}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the buffalo.App for routing.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (b *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return b.Handle(ctx, req, b.App)
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (b *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return b.Stream(ctx, req, b.App)
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (b *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return b.HandleRaw(ctx, payload, b.App)
+}
+
+// GetALBContext retrieve the ALBTargetGroupRequestContext from the context of
+// a Buffalo action.
+func GetALBContext(c buffalo.Context) (events.ALBTargetGroupRequestContext, bool) {
+	return core.GetALBContextFromContext(c.Request().Context())
+}
+
+// GetRuntimeContext retrieve the Lambda Runtime Context from the context of
+// a Buffalo action.
+func GetRuntimeContext(c buffalo.Context) (*lambdacontext.LambdaContext, bool) {
+	return core.GetRuntimeContextFromContext(c.Request().Context())
+}
@@ -0,0 +1,97 @@
+// Package gojiadapter adds goji support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to the goji.Mux
+package gojiadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/toff63/lambda-http-adapter/core"
+	"goji.io"
+)
+
+func init() {
+	core.RegisterAdapter("goji", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		g, ok := app.(*goji.Mux)
+		if !ok {
+			return nil, fmt.Errorf("gojiadapter: %T is not a *goji.Mux", app)
+
+		}
+		return New(g, opts...), nil
+
+	})
+
+}
+
+// GojiLambda makes it easy to send ALB events to a goji.Mux.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter
+type GojiLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the GojiLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Goji *goji.Mux
+}
+
+// New creates a new instance of the GojiLambda object.
+// Receives an initialized *goji.Mux object - normally created with goji.NewMux().
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the GojiLambda object.
+func New(g *goji.Mux, opts ...core.Option) *GojiLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](g, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](g *goji.Mux, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Goji: g}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the goji.Mux for routing.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (g *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return g.Handle(ctx, req, g.Goji)
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (g *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return g.Stream(ctx, req, g.Goji)
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (g *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return g.HandleRaw(ctx, payload, g.Goji)
+}
+
+// GetALBContext retrieve the ALBTargetGroupRequestContext of a request routed
+// by goji, for use in handlers and middlewares.
+func GetALBContext(r *http.Request) (events.ALBTargetGroupRequestContext, bool) {
+	return core.GetALBContextFromContext(r.Context())
+}
+
+// GetRuntimeContext retrieve the Lambda Runtime Context of a request routed
+// by goji, for use in handlers and middlewares.
+func GetRuntimeContext(r *http.Request) (*lambdacontext.LambdaContext, bool) {
+	return core.GetRuntimeContextFromContext(r.Context())
+}
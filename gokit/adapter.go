@@ -0,0 +1,94 @@
+// Package gokitadapter adds go-kit HTTP transport support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to go-kit servers
+package gokitadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+func init() {
+	core.RegisterAdapter("gokit", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		handler, ok := app.(http.Handler)
+		if !ok {
+			return nil, fmt.Errorf("gokitadapter: %T is not an http.Handler", app)
+
+		}
+		return New(handler, opts...), nil
+
+	})
+
+}
+
+// GoKitLambda makes it easy to send ALB events to go-kit HTTP servers.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter
+type GoKitLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the GoKitLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Handler http.Handler
+}
+
+// New creates a new instance of the GoKitLambda object.
+// Receives the http.Handler serving the go-kit endpoints - a single
+// *kithttp.Server or a router the servers are mounted on.
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the GoKitLambda object.
+func New(handler http.Handler, opts ...core.Option) *GoKitLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](handler, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](handler http.Handler, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Handler: handler}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the go-kit servers.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (g *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return g.Handle(ctx, req, g.Handler)
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (g *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return g.Stream(ctx, req, g.Handler)
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (g *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return g.HandleRaw(ctx, payload, g.Handler)
+}
+
+// PopulateLambdaContext is a kithttp.RequestFunc, to register with
+// kithttp.ServerBefore, that attaches the ALB and Lambda runtime contexts of
+// the request to the go-kit request-scoped context, so that endpoints and
+// decoders can use core.GetALBContextFromContext and
+// core.GetRuntimeContextFromContext on the context they receive.
+var PopulateLambdaContext kithttp.RequestFunc = func(ctx context.Context, r *http.Request) context.Context {
+	return core.CopyRequestContext(ctx, r.Context())
+}
@@ -0,0 +1,84 @@
+// Package gorillamuxadapter adds Gorilla mux support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to the mux.Router
+package gorillamuxadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gorilla/mux"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+func init() {
+	core.RegisterAdapter("gorillamux", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		router, ok := app.(*mux.Router)
+		if !ok {
+			return nil, fmt.Errorf("gorillamuxadapter: %T is not a *mux.Router", app)
+
+		}
+		return New(router, opts...), nil
+
+	})
+
+}
+
+// GorillaMuxLambda makes it easy to send ALB events to a mux.Router.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter.
+// Handlers read the ALB request context with core.GetALBContextFromContext(r.Context()).
+type GorillaMuxLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the GorillaMuxLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Router *mux.Router
+}
+
+// New creates a new instance of the GorillaMuxLambda object.
+// Receives an initialized *mux.Router object - normally created with mux.NewRouter().
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the GorillaMuxLambda object.
+func New(router *mux.Router, opts ...core.Option) *GorillaMuxLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](router, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](router *mux.Router, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Router: router}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the mux.Router for routing.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (g *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return g.Handle(ctx, req, g.Router)
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (g *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return g.Stream(ctx, req, g.Router)
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (g *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return g.HandleRaw(ctx, payload, g.Router)
+}
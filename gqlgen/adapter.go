@@ -0,0 +1,151 @@
+// Package gqlgenadapter adds gqlgen GraphQL support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to the gqlgen server
+package gqlgenadapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+// GqlgenLambda makes it easy to send ALB events to a gqlgen server, such as
+// the one returned by handler.NewDefaultServer, which serves queries sent
+// with both GET and POST.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter.
+// Resolvers reach the ALB and Lambda runtime contexts through the context
+// they receive with core.GetALBContextFromContext and
+// core.GetRuntimeContextFromContext.
+//
+// POST requests whose JSON body is an array of operations are batched: each
+// operation is executed in turn and the response is the JSON array of the
+// individual results, in the same order.
+type GqlgenLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the GqlgenLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Handler http.Handler
+
+	// MaxBatchSize limits the number of operations of a batch. Larger batches
+	// are rejected with a 400. Zero means no limit.
+	MaxBatchSize int
+}
+
+// New creates a new instance of the GqlgenLambda object.
+// Receives the gqlgen server - normally created with handler.NewDefaultServer().
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the GqlgenLambda object.
+func New(handler http.Handler, opts ...core.Option) *GqlgenLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](handler, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](handler http.Handler, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Handler: handler}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the gqlgen server.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (g *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return g.Handle(ctx, req, http.HandlerFunc(g.serveHTTP))
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (g *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return g.Stream(ctx, req, http.HandlerFunc(g.serveHTTP))
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (g *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return g.HandleRaw(ctx, payload, http.HandlerFunc(g.serveHTTP))
+}
+
+// serveHTTP sends single operations straight to the gqlgen server and splits
+// batches into one request per operation.
+func (g *Lambda[E, R]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		g.Handler.ServeHTTP(w, r)
+		return
+
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		g.Handler.ServeHTTP(w, r)
+		return
+
+	}
+
+	var operations []json.RawMessage
+	if err := json.Unmarshal(body, &operations); err != nil {
+		http.Error(w, "invalid batch: "+err.Error(), http.StatusBadRequest)
+		return
+
+	}
+	if g.MaxBatchSize > 0 && len(operations) > g.MaxBatchSize {
+		http.Error(w, "too many operations in batch", http.StatusBadRequest)
+		return
+
+	}
+
+	results := make([]json.RawMessage, len(operations))
+	for i, operation := range operations {
+		results[i] = g.execute(r, operation)
+
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+
+}
+
+// execute runs a single operation of a batch and returns its JSON result.
+func (g *Lambda[E, R]) execute(batch *http.Request, operation json.RawMessage) json.RawMessage {
+	r := batch.Clone(batch.Context())
+	r.Body = io.NopCloser(bytes.NewReader(operation))
+	r.ContentLength = int64(len(operation))
+
+	w := core.NewProxyResponseWriter()
+	g.Handler.ServeHTTP(w, r)
+	result := w.Body()
+	if json.Valid(result) {
+		return result
+
+	}
+
+	// Keep the batch response valid JSON when the server answered with text.
+	wrapped, _ := json.Marshal(map[string][]map[string]string{
+		"errors": {{"message": string(bytes.TrimSpace(result))}},
+	})
+	return wrapped
+
+}
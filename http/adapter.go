@@ -0,0 +1,102 @@
+// Package httpadapter adds net/http support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to any http.Handler,
+// such as an http.ServeMux with Go 1.22 pattern routing.
+package httpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+func init() {
+	core.RegisterAdapter("http", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		handler, ok := app.(http.Handler)
+		if !ok {
+			return nil, fmt.Errorf("httpadapter: %T is not an http.Handler", app)
+
+		}
+		return New(handler, opts...), nil
+
+	})
+
+}
+
+// HandlerAdapter makes it easy to send ALB events to an http.Handler.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter
+type HandlerAdapter = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the HandlerAdapter of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Handler http.Handler
+}
+
+// New creates a new instance of the HandlerAdapter object.
+// Receives the http.Handler requests are sent to, for instance an
+// *http.ServeMux created with http.NewServeMux().
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the HandlerAdapter object.
+func New(handler http.Handler, opts ...core.Option) *HandlerAdapter {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](handler, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](handler http.Handler, opts ...core.Option) *Lambda[E, R] {
+	h := &Lambda[E, R]{Handler: handler}
+	h.Configure(opts...)
+	return h
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the http.Handler.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (h *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return h.Handle(ctx, req, withPathValues(h.Handler))
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (h *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return h.Stream(ctx, req, withPathValues(h.Handler))
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (h *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return h.HandleRaw(ctx, payload, withPathValues(h.Handler))
+}
+
+// withPathValues sets the API Gateway path parameters of the request as its
+// path values, so handlers reading Request.PathValue work the same behind an
+// API Gateway route such as /items/{id} and behind an http.ServeMux, which
+// replaces them with the ones of its own pattern.
+func withPathValues(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params, _ := core.GetPathParametersFromContext(r.Context())
+		for name, value := range params {
+			r.SetPathValue(name, value)
+
+		}
+		h.ServeHTTP(w, r)
+
+	})
+
+}
@@ -0,0 +1,85 @@
+// Package httprouteradapter adds julienschmidt/httprouter support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to the httprouter.Router
+package httprouteradapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/julienschmidt/httprouter"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+func init() {
+	core.RegisterAdapter("httprouter", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		router, ok := app.(*httprouter.Router)
+		if !ok {
+			return nil, fmt.Errorf("httprouteradapter: %T is not a *httprouter.Router", app)
+
+		}
+		return New(router, opts...), nil
+
+	})
+
+}
+
+// HTTPRouterLambda makes it easy to send ALB events to a httprouter.Router.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter.
+// The router matches on the decoded URL.Path and hands route parameters to
+// handlers through httprouter.Params as usual.
+type HTTPRouterLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the HTTPRouterLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Router *httprouter.Router
+}
+
+// New creates a new instance of the HTTPRouterLambda object.
+// Receives an initialized *httprouter.Router object - normally created with httprouter.New().
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the HTTPRouterLambda object.
+func New(router *httprouter.Router, opts ...core.Option) *HTTPRouterLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](router, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](router *httprouter.Router, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Router: router}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the httprouter.Router for routing.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (h *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return h.Handle(ctx, req, h.Router)
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (h *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return h.Stream(ctx, req, h.Router)
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (h *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return h.HandleRaw(ctx, payload, h.Router)
+}
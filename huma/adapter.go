@@ -0,0 +1,147 @@
+// Package humaadapter adds Huma support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to the huma.API
+package humaadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+func init() {
+	core.RegisterAdapter("huma", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		api, ok := app.(huma.API)
+		if !ok {
+			return nil, fmt.Errorf("humaadapter: %T is not a huma.API", app)
+
+		}
+		return New(api, opts...), nil
+
+	})
+
+}
+
+// TimeoutMetadataKey is the huma.Operation metadata key holding the
+// time.Duration an operation is allowed to run for.
+const TimeoutMetadataKey = "timeout"
+
+// HumaLambda makes it easy to send ALB events to a huma.API.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter
+type HumaLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the HumaLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	API huma.API
+}
+
+// New creates a new instance of the HumaLambda object.
+// Receives an initialized huma.API object - normally created with one of the
+// Huma router adapters, such as humago.New().
+// A middleware bounding operations by their timeout and the Lambda deadline
+// is registered on the API, once however many Lambdas serve it. Huma applies
+// middlewares when operations are registered, so New must be called before
+// huma.Register and panics otherwise.
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the HumaLambda object.
+func New(api huma.API, opts ...core.Option) *HumaLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](api, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](api huma.API, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{API: api}
+	l.Configure(opts...)
+	useTimeout(api)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the huma.API for routing.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (h *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return h.Handle(ctx, req, h.API.Adapter())
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (h *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return h.Stream(ctx, req, h.API.Adapter())
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (h *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return h.HandleRaw(ctx, payload, h.API.Adapter())
+}
+
+// useTimeout registers the timeout middleware on api unless it already is.
+func useTimeout(api huma.API) {
+	for _, m := range api.Middlewares() {
+		if reflect.ValueOf(m).Pointer() == reflect.ValueOf(timeout).Pointer() {
+			return
+
+		}
+
+	}
+	if len(api.OpenAPI().Paths) > 0 {
+		panic("humaadapter: New called after operations were registered, they would run without their timeout")
+
+	}
+	api.UseMiddleware(timeout)
+
+}
+
+// timeout gives the operation context the deadline of the operation timeout
+// set in its TimeoutMetadataKey metadata. The request context already carries
+// the Lambda deadline, so the earliest of both applies.
+func timeout(ctx huma.Context, next func(huma.Context)) {
+	op := ctx.Operation()
+	if op == nil {
+		next(ctx)
+		return
+
+	}
+	d, ok := op.Metadata[TimeoutMetadataKey].(time.Duration)
+	if !ok || d <= 0 {
+		next(ctx)
+		return
+
+	}
+	opCtx, cancel := context.WithTimeout(ctx.Context(), d)
+	defer cancel()
+	next(huma.WithContext(ctx, opCtx))
+
+}
+
+// GetALBContext retrieve the ALBTargetGroupRequestContext from the context
+// given to a Huma operation handler.
+func GetALBContext(ctx context.Context) (events.ALBTargetGroupRequestContext, bool) {
+	return core.GetALBContextFromContext(ctx)
+}
+
+// GetRuntimeContext retrieve the Lambda Runtime Context from the context
+// given to a Huma operation handler.
+func GetRuntimeContext(ctx context.Context) (*lambdacontext.LambdaContext, bool) {
+	return core.GetRuntimeContextFromContext(ctx)
+}
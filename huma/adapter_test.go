@@ -0,0 +1,61 @@
+package humaadapter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humago"
+)
+
+func TestTimeout(t *testing.T) {
+	api := humago.New(http.NewServeMux(), huma.DefaultConfig("test", "1.0.0"))
+	l := New(api)
+	New(api)
+	if n := len(api.Middlewares()); n != 1 {
+		t.Fatalf("got %d middlewares, want 1", n)
+
+	}
+
+	var hasDeadline bool
+	huma.Register(api, huma.Operation{
+		Method:   http.MethodGet,
+		Path:     "/slow",
+		Metadata: map[string]any{TimeoutMetadataKey: time.Second},
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		_, hasDeadline = ctx.Deadline()
+		return nil, nil
+
+	})
+	req := events.ALBTargetGroupRequest{HTTPMethod: http.MethodGet, Path: "/slow"}
+	resp, err := l.ProxyWithContext(context.Background(), req)
+	if err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("got %d, %v, want 204", resp.StatusCode, err)
+
+	}
+	if !hasDeadline {
+		t.Error("the operation context has no deadline")
+
+	}
+
+}
+
+func TestNewAfterRegister(t *testing.T) {
+	api := humago.New(http.NewServeMux(), huma.DefaultConfig("test", "1.0.0"))
+	huma.Get(api, "/", func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		return nil, nil
+
+	})
+	defer func() {
+		if recover() == nil {
+			t.Error("New did not panic")
+
+		}
+
+	}()
+	New(api)
+
+}
@@ -0,0 +1,118 @@
+// Package irisadapter adds Iris support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to the iris.Application
+package irisadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/kataras/iris/v12"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+func init() {
+	core.RegisterAdapter("iris", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		a, ok := app.(*iris.Application)
+		if !ok {
+			return nil, fmt.Errorf("irisadapter: %T is not an *iris.Application", app)
+
+		}
+		return New(a, opts...), nil
+
+	})
+
+}
+
+// IrisLambda makes it easy to send ALB events to an iris.Application.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter
+type IrisLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the IrisLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Application *iris.Application
+
+	buildOnce sync.Once
+	buildErr  error
+}
+
+// New creates a new instance of the IrisLambda object.
+// Receives an initialized *iris.Application object - normally created with iris.New().
+// The application is built on the first proxied event, as iris.Application.Listen
+// would do; do not call Listen or Run.
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the IrisLambda object.
+func New(app *iris.Application, opts ...core.Option) *IrisLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](app, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](app *iris.Application, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Application: app}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the iris.Application for routing.
+// Iris releases its request context, which flushes any buffered or recorded
+// response into the http.ResponseWriter, before ServeHTTP returns, so the
+// response is complete when it is converted.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (i *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	if err := i.build(); err != nil {
+		return core.FromALBResponse[R](core.TimeoutResponse()), err
+
+	}
+	return i.Handle(ctx, req, i.Application)
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (i *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	if err := i.build(); err != nil {
+		return nil, err
+
+	}
+	return i.Stream(ctx, req, i.Application)
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (i *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	if err := i.build(); err != nil {
+		return nil, err
+
+	}
+	return i.HandleRaw(ctx, payload, i.Application)
+}
+
+// build builds the iris.Application on the first call.
+func (i *Lambda[E, R]) build() error {
+	i.buildOnce.Do(func() {
+		i.buildErr = i.Application.Build()
+
+	})
+	if i.buildErr != nil {
+		return core.NewLoggedError("Could not build iris application: %v", i.buildErr)
+
+	}
+	return nil
+
+}
@@ -0,0 +1,108 @@
+// Package lambdaurladapter eases the migration between the lambdaurl package
+// of aws-lambda-go and this library. Wrap is a drop-in replacement for
+// lambdaurl.Wrap that goes through the core package, and WrapALB mounts the
+// same http.Handler behind an ALB. Handlers written against
+// lambdaurl.RequestFromContext switch to RequestFromContext of this package.
+package lambdaurladapter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/toff63/lambda-http-adapter/core"
+	httpadapter "github.com/toff63/lambda-http-adapter/http"
+)
+
+// Wrap converts an http.Handler into a handler for Function URL events in
+// RESPONSE_STREAM mode, with the signature of lambdaurl.Wrap.
+// Options configure the underlying core.Accessor.
+func Wrap(handler http.Handler, opts ...core.Option) func(context.Context, *events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+	l := httpadapter.NewLambda[events.LambdaFunctionURLRequest, events.LambdaFunctionURLResponse](handler, opts...)
+	return func(ctx context.Context, req *events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+		ctx = context.WithValue(ctx, ctxKey{}, req)
+		return l.StartStreaming(ctx, *req)
+
+	}
+
+}
+
+// WrapALB converts an http.Handler written for lambdaurl.Wrap into a handler
+// for ALB events. RequestFromContext returns the Function URL event
+// equivalent to the ALB event.
+// Options configure the underlying core.RequestAccessor.
+func WrapALB(handler http.Handler, opts ...core.Option) func(context.Context, events.ALBTargetGroupRequest) (events.ALBTargetGroupResponse, error) {
+	l := httpadapter.New(handler, opts...)
+	return func(ctx context.Context, req events.ALBTargetGroupRequest) (events.ALBTargetGroupResponse, error) {
+		ctx = context.WithValue(ctx, ctxKey{}, functionURLRequest(req))
+		return l.ProxyWithContext(ctx, req)
+
+	}
+
+}
+
+type ctxKey struct{}
+
+// RequestFromContext returns the Function URL event of a request served by a
+// handler returned by Wrap or WrapALB, like lambdaurl.RequestFromContext.
+func RequestFromContext(ctx context.Context) (*events.LambdaFunctionURLRequest, bool) {
+	req, ok := ctx.Value(ctxKey{}).(*events.LambdaFunctionURLRequest)
+	return req, ok
+}
+
+// functionURLRequest returns the Function URL event equivalent to an ALB event.
+func functionURLRequest(req events.ALBTargetGroupRequest) *events.LambdaFunctionURLRequest {
+	query := url.Values(req.MultiValueQueryStringParameters)
+	if len(query) == 0 {
+		query = make(url.Values, len(req.QueryStringParameters))
+		for name, value := range req.QueryStringParameters {
+			query.Set(name, value)
+
+		}
+
+	}
+
+	header := core.AsHTTPEvent(req).Header()
+	headers := make(map[string]string, len(header))
+	var cookies []string
+	for name, values := range header {
+		if name == "Cookie" {
+			for _, v := range values {
+				cookies = append(cookies, strings.Split(v, "; ")...)
+
+			}
+			continue
+
+		}
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+
+	}
+	sourceIP := headers["x-forwarded-for"]
+	if i := strings.LastIndex(sourceIP, ","); i >= 0 {
+		sourceIP = sourceIP[i+1:]
+
+	}
+
+	return &events.LambdaFunctionURLRequest{
+		Version:               "2.0",
+		RawPath:               req.Path,
+		RawQueryString:        query.Encode(),
+		Cookies:               cookies,
+		Headers:               headers,
+		QueryStringParameters: req.QueryStringParameters,
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method:    req.HTTPMethod,
+				Path:      req.Path,
+				Protocol:  "HTTP/1.1",
+				SourceIP:  strings.TrimSpace(sourceIP),
+				UserAgent: headers["user-agent"],
+			},
+		},
+		Body:            req.Body,
+		IsBase64Encoded: req.IsBase64Encoded,
+	}
+
+}
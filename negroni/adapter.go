@@ -0,0 +1,85 @@
+// Package negroniadapter adds Negroni support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send request to the negroni.Negroni
+package negroniadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/toff63/lambda-http-adapter/core"
+	"github.com/urfave/negroni"
+)
+
+func init() {
+	core.RegisterAdapter("negroni", func(app any, opts ...core.Option) (core.Adapter[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse], error) {
+		n, ok := app.(*negroni.Negroni)
+		if !ok {
+			return nil, fmt.Errorf("negroniadapter: %T is not a *negroni.Negroni", app)
+
+		}
+		return New(n, opts...), nil
+
+	})
+
+}
+
+// NegroniLambda makes it easy to send ALB events to a negroni.Negroni stack.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter.
+// Every middleware of the stack runs in order with the request context
+// carrying the ALB and Lambda runtime contexts.
+type NegroniLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the NegroniLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Negroni *negroni.Negroni
+}
+
+// New creates a new instance of the NegroniLambda object.
+// Receives an initialized *negroni.Negroni object - normally created with negroni.Classic().
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the NegroniLambda object.
+func New(n *negroni.Negroni, opts ...core.Option) *NegroniLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](n, opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](n *negroni.Negroni, opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Negroni: n}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it through the negroni.Negroni stack.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (n *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return n.Handle(ctx, req, n.Negroni)
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (n *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return n.Stream(ctx, req, n.Negroni)
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (n *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return n.HandleRaw(ctx, payload, n.Negroni)
+}
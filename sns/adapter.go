@@ -0,0 +1,113 @@
+// Package snsadapter adds SNS support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send each notification of an SNS
+// event to an http.Handler as a synthetic HTTP request, so webhook-style
+// handlers process pub/sub traffic too.
+package snsadapter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+// SNSLambda makes it easy to send SNS notifications to an http.Handler.
+// Each notification becomes a POST request whose body is the message and
+// whose headers are the ones SNS sends to HTTP subscriptions, such as
+// X-Amz-Sns-Message-Id and X-Amz-Sns-Topic-Arn. The path is the name of the
+// topic followed by the subject, if any: /orders/Order%20shipped.
+type SNSLambda struct {
+	core.RequestAccessor
+
+	Handler http.Handler
+
+	// Path replaces the default derivation of the request path when set.
+	Path func(events.SNSEntity) string
+}
+
+// New creates a new instance of the SNSLambda object.
+// Receives the http.Handler notifications are sent to.
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the SNSLambda object.
+func New(handler http.Handler, opts ...core.Option) *SNSLambda {
+	l := &SNSLambda{Handler: handler}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an SNS event and sends each of its
+// notifications to the http.Handler.
+// It returns an error if a notification could not be converted or was
+// answered with a status code of 400 or more, so that Lambda retries the event.
+func (s *SNSLambda) ProxyWithContext(ctx context.Context, ev events.SNSEvent) error {
+	for _, record := range ev.Records {
+		recordCtx := context.WithValue(ctx, ctxKey{}, record.SNS)
+		resp, err := s.Handle(recordCtx, s.toALBRequest(record), s.Handler)
+		if err != nil {
+			return err
+
+		}
+		if resp.StatusCode >= http.StatusBadRequest {
+			return core.NewLoggedError("Notification %s was answered with status %d", record.SNS.MessageID, resp.StatusCode)
+
+		}
+
+	}
+	return nil
+}
+
+// toALBRequest converts a notification into the ALB event of its request.
+func (s *SNSLambda) toALBRequest(record events.SNSEventRecord) events.ALBTargetGroupRequest {
+	path := s.Path
+	if path == nil {
+		path = defaultPath
+
+	}
+	return events.ALBTargetGroupRequest{
+		HTTPMethod: http.MethodPost,
+		Path:       path(record.SNS),
+		Headers: map[string]string{
+			"Content-Type":               "text/plain; charset=UTF-8",
+			"X-Amz-Sns-Message-Type":     orDefault(record.SNS.Type, "Notification"),
+			"X-Amz-Sns-Message-Id":       record.SNS.MessageID,
+			"X-Amz-Sns-Topic-Arn":        record.SNS.TopicArn,
+			"X-Amz-Sns-Subscription-Arn": record.EventSubscriptionArn,
+		},
+		Body: record.SNS.Message,
+	}
+
+}
+
+// defaultPath returns the name of the topic followed by the subject.
+func defaultPath(entity events.SNSEntity) string {
+	path := "/" + entity.TopicArn[strings.LastIndex(entity.TopicArn, ":")+1:]
+	if entity.Subject != "" {
+		path += "/" + url.PathEscape(entity.Subject)
+
+	}
+	return path
+
+}
+
+func orDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+
+	}
+	return value
+
+}
+
+type ctxKey struct{}
+
+// GetNotification retrieve the SNSEntity a request was built from, for use in
+// handlers and middlewares.
+func GetNotification(r *http.Request) (events.SNSEntity, bool) {
+	v, ok := r.Context().Value(ctxKey{}).(events.SNSEntity)
+	return v, ok
+}
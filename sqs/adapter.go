@@ -0,0 +1,163 @@
+// Package sqsadapter adds SQS support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send each message of an SQS event
+// to an http.Handler as a synthetic HTTP request, so queue consumers reuse
+// the handlers of the HTTP stack.
+package sqsadapter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+// Message attributes read by default to build the request of a message.
+const (
+	DefaultMethodAttribute       = "http-method"
+	DefaultPathAttribute         = "http-path"
+	DefaultHeaderAttributePrefix = "http-header-"
+)
+
+// SQSLambda makes it easy to send SQS messages to an http.Handler.
+// Each message becomes a request whose body is the message body. Its method
+// and path, which may carry a query string, come from the message attributes
+// named MethodAttribute and PathAttribute, and default to POST and to the
+// name of the queue, such as /orders. Each string attribute named
+// HeaderAttributePrefix followed by a header name becomes a header, except
+// X-Sqs-Message-Id which always holds the ID of the message.
+// Messages answered with a status code of 400 or more are reported as batch
+// item failures, so only they are retried when the event source mapping
+// reports batch item failures. For FIFO queues, the first failure stops the
+// batch and the remaining messages are reported as failures too, so they are
+// retried in order.
+type SQSLambda struct {
+	core.RequestAccessor
+
+	Handler http.Handler
+
+	// MethodAttribute, PathAttribute and HeaderAttributePrefix replace the
+	// default attribute names when set.
+	MethodAttribute       string
+	PathAttribute         string
+	HeaderAttributePrefix string
+}
+
+// New creates a new instance of the SQSLambda object.
+// Receives the http.Handler messages are sent to.
+// Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the SQSLambda object.
+func New(handler http.Handler, opts ...core.Option) *SQSLambda {
+	l := &SQSLambda{Handler: handler}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an SQS event and sends each of its
+// messages, in order, to the http.Handler.
+// It returns the messages that failed, either because their conversion failed
+// or because the handler answered with an error status.
+func (s *SQSLambda) ProxyWithContext(ctx context.Context, ev events.SQSEvent) (events.SQSEventResponse, error) {
+	var resp events.SQSEventResponse
+	for i, msg := range ev.Records {
+		msgCtx := context.WithValue(ctx, ctxKey{}, msg)
+		albResp, err := s.Handle(msgCtx, s.toALBRequest(msg), s.Handler)
+		if err == nil && albResp.StatusCode < http.StatusBadRequest {
+			continue
+
+		}
+		if isFIFO(msg.EventSourceARN) {
+			for _, remaining := range ev.Records[i:] {
+				resp.BatchItemFailures = append(resp.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: remaining.MessageId})
+
+			}
+			break
+
+		}
+		resp.BatchItemFailures = append(resp.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: msg.MessageId})
+
+	}
+	return resp, nil
+}
+
+// toALBRequest converts a message into the ALB event of its request.
+func (s *SQSLambda) toALBRequest(msg events.SQSMessage) events.ALBTargetGroupRequest {
+	req := events.ALBTargetGroupRequest{
+		HTTPMethod: http.MethodPost,
+		Path:       "/" + queueName(msg.EventSourceARN),
+		Headers:    map[string]string{},
+		Body:       msg.Body,
+	}
+
+	methodAttribute := orDefault(s.MethodAttribute, DefaultMethodAttribute)
+	pathAttribute := orDefault(s.PathAttribute, DefaultPathAttribute)
+	headerPrefix := orDefault(s.HeaderAttributePrefix, DefaultHeaderAttributePrefix)
+	for name, attr := range msg.MessageAttributes {
+		if attr.StringValue == nil {
+			continue
+
+		}
+		switch {
+		case name == methodAttribute:
+			req.HTTPMethod = *attr.StringValue
+
+		case name == pathAttribute:
+			path, query, _ := strings.Cut(*attr.StringValue, "?")
+			req.Path = path
+			if query != "" {
+				req.MultiValueQueryStringParameters, _ = url.ParseQuery(query)
+
+			}
+
+		case strings.HasPrefix(name, headerPrefix) && len(name) > len(headerPrefix):
+			req.Headers[name[len(headerPrefix):]] = *attr.StringValue
+
+		}
+
+	}
+	// Set last so that a header attribute cannot replace the message ID.
+	for name := range req.Headers {
+		if strings.EqualFold(name, "X-Sqs-Message-Id") {
+			delete(req.Headers, name)
+
+		}
+
+	}
+	req.Headers["X-Sqs-Message-Id"] = msg.MessageId
+	return req
+
+}
+
+// queueName returns the name of the queue of an SQS ARN.
+func queueName(arn string) string {
+	return arn[strings.LastIndex(arn, ":")+1:]
+
+}
+
+// isFIFO reports whether an SQS ARN is the one of a FIFO queue.
+func isFIFO(arn string) bool {
+	return strings.HasSuffix(arn, ".fifo")
+
+}
+
+func orDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+
+	}
+	return value
+
+}
+
+type ctxKey struct{}
+
+// GetMessage retrieve the SQSMessage a request was built from, for use in
+// handlers and middlewares.
+func GetMessage(r *http.Request) (events.SQSMessage, bool) {
+	v, ok := r.Context().Value(ctxKey{}).(events.SQSMessage)
+	return v, ok
+}
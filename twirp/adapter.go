@@ -0,0 +1,91 @@
+// Package twirpadapter adds Twirp support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance, the Mount method to register Twirp servers and Proxy
+// method to send request to them.
+package twirpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+// BinaryContentTypes are the protobuf media types used by Twirp. Their bodies
+// are always base64 encoded in ALB responses.
+var BinaryContentTypes = []string{"application/protobuf"}
+
+// Server is implemented by the servers generated by protoc-gen-twirp.
+type Server interface {
+	http.Handler
+	PathPrefix() string
+}
+
+// TwirpLambda makes it easy to send ALB events to Twirp servers.
+// The library transforms the ALB event into an HTTP request and then
+// creates an ALB response object from the http.ResponseWriter.
+// Protobuf request bodies are base64 decoded by the core package, and Twirp
+// error envelopes, which are JSON, are returned untouched with their status.
+type TwirpLambda = Lambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse]
+
+// Lambda is the TwirpLambda of events of type E, answered with responses of type R,
+// for functions behind another trigger than ALB.
+type Lambda[E core.Event, R core.Response] struct {
+	core.Accessor[E, R]
+
+	Mux *http.ServeMux
+}
+
+// New creates a new instance of the TwirpLambda object with an empty
+// http.ServeMux. Responses with one of the BinaryContentTypes are always base64
+// encoded. Options configure the underlying core.RequestAccessor.
+// It returns the initialized instance of the TwirpLambda object.
+func New(opts ...core.Option) *TwirpLambda {
+	return NewLambda[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](opts...)
+
+}
+
+// NewLambda creates a new instance of the Lambda object serving events of
+// type E with responses of type R. It takes the same arguments as New, which
+// serves ALB events.
+func NewLambda[E core.Event, R core.Response](opts ...core.Option) *Lambda[E, R] {
+	l := &Lambda[E, R]{Mux: http.NewServeMux()}
+	l.Configure(core.WithBinaryContentTypes(BinaryContentTypes...))
+	l.Configure(opts...)
+	return l
+
+}
+
+// Mount registers a Twirp server under its path prefix:
+//
+//	l.Mount(haberdasher.NewHaberdasherServer(&server{}))
+func (t *Lambda[E, R]) Mount(server Server) {
+	t.Mux.Handle(server.PathPrefix(), server)
+
+}
+
+// ProxyWithContext receives context and an ALB event,
+// transforms them into an http.Request object, and sends it to the mounted Twirp servers.
+// It returns an ALB response object generated from the http.ResponseWriter.
+func (t *Lambda[E, R]) ProxyWithContext(ctx context.Context, req E) (R, error) {
+	return t.Handle(ctx, req, t.Mux)
+}
+
+// StartStreaming receives context and an event and serves it like
+// ProxyWithContext, but returns as soon as the handler starts responding with
+// a response whose body streams what the handler writes next. Pass it to
+// lambda.Start for functions behind a Function URL in RESPONSE_STREAM mode.
+// See core.Accessor.Stream for the options that do not apply to it.
+func (t *Lambda[E, R]) StartStreaming(ctx context.Context, req E) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return t.Stream(ctx, req, t.Mux)
+}
+
+// ProxyRawWithContext receives context and an event of any supported trigger,
+// detects its type and serves it like ProxyWithContext. It returns the
+// response type of the detected trigger, so a single function can be attached
+// to all of them.
+func (t *Lambda[E, R]) ProxyRawWithContext(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+	return t.HandleRaw(ctx, payload, t.Mux)
+}
@@ -0,0 +1,98 @@
+// Package websocketadapter adds API Gateway WebSocket API support for the library.
+// Uses the core package behind the scenes and exposes the New method to
+// get a new instance and Proxy method to send the messages of a WebSocket API
+// to an http.Handler, so WebSocket routes share the routing of the HTTP stack.
+package websocketadapter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/toff63/lambda-http-adapter/core"
+)
+
+// DefaultPathPrefix is the path prefix of the requests the WebSocket routes
+// are mapped onto.
+const DefaultPathPrefix = "/ws"
+
+// WebsocketLambda makes it easy to send API Gateway WebSocket events to an
+// http.Handler. Each event becomes a POST request to the path of its route:
+// $connect, $disconnect and $default are sent to /ws/connect, /ws/disconnect
+// and /ws/default and a custom route such as sendMessage to /ws/sendMessage.
+type WebsocketLambda struct {
+	core.Accessor[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse]
+
+	Handler http.Handler
+
+	// PathPrefix replaces DefaultPathPrefix when set.
+	PathPrefix string
+}
+
+// New creates a new instance of the WebsocketLambda object.
+// Receives the http.Handler the routes are sent to.
+// Options configure the underlying core.Accessor.
+// It returns the initialized instance of the WebsocketLambda object.
+func New(handler http.Handler, opts ...core.Option) *WebsocketLambda {
+	l := &WebsocketLambda{Handler: handler}
+	l.Configure(opts...)
+	return l
+
+}
+
+// ProxyWithContext receives context and an API Gateway WebSocket event,
+// transforms them into an http.Request object, and sends it to the http.Handler.
+// It returns a response object generated from the http.ResponseWriter; its
+// body is sent back to the client for routes with a route response.
+func (w *WebsocketLambda) ProxyWithContext(ctx context.Context, req events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx = context.WithValue(ctx, ctxKey{}, req.RequestContext)
+	return w.Handle(ctx, w.toProxyRequest(req), w.Handler)
+}
+
+// RoutePath returns the path of the requests a route is mapped onto.
+func (w *WebsocketLambda) RoutePath(routeKey string) string {
+	prefix := w.PathPrefix
+	if prefix == "" {
+		prefix = DefaultPathPrefix
+
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + url.PathEscape(strings.TrimPrefix(routeKey, "$"))
+
+}
+
+// toProxyRequest converts a WebSocket event into the REST API event of a
+// POST request to the path of its route.
+func (w *WebsocketLambda) toProxyRequest(req events.APIGatewayWebsocketProxyRequest) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		Path:                            w.RoutePath(req.RequestContext.RouteKey),
+		HTTPMethod:                      http.MethodPost,
+		Headers:                         req.Headers,
+		MultiValueHeaders:               req.MultiValueHeaders,
+		QueryStringParameters:           req.QueryStringParameters,
+		MultiValueQueryStringParameters: req.MultiValueQueryStringParameters,
+		StageVariables:                  req.StageVariables,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			AccountID:  req.RequestContext.AccountID,
+			Stage:      req.RequestContext.Stage,
+			RequestID:  req.RequestContext.RequestID,
+			Identity:   req.RequestContext.Identity,
+			APIID:      req.RequestContext.APIID,
+			DomainName: req.RequestContext.DomainName,
+		},
+		Body:            req.Body,
+		IsBase64Encoded: req.IsBase64Encoded,
+	}
+
+}
+
+type ctxKey struct{}
+
+// GetWebsocketContext retrieve the APIGatewayWebsocketProxyRequestContext of
+// a request, holding the connection ID and the route key, for use in handlers
+// and middlewares.
+func GetWebsocketContext(r *http.Request) (events.APIGatewayWebsocketProxyRequestContext, bool) {
+	v, ok := r.Context().Value(ctxKey{}).(events.APIGatewayWebsocketProxyRequestContext)
+	return v, ok
+}
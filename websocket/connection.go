@@ -0,0 +1,93 @@
+package websocketadapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/apigatewaymanagementapi"
+)
+
+// ErrConnectionGone is returned by PostToConnection when the client is no
+// longer connected. The connection ID should then be forgotten.
+var ErrConnectionGone = errors.New("websocketadapter: connection is gone")
+
+// Client sends messages to the clients connected to a WebSocket API through
+// the API Gateway Management API.
+type Client struct {
+	api *apigatewaymanagementapi.ApiGatewayManagementApi
+}
+
+// NewClient creates a Client for the Management API of endpoint, as returned
+// by Endpoint, with the AWS configuration of p, normally a *session.Session.
+func NewClient(p client.ConfigProvider, endpoint string) *Client {
+	return &Client{api: apigatewaymanagementapi.New(p, aws.NewConfig().WithEndpoint(endpoint))}
+
+}
+
+// NewClientFromRequest creates a Client for the WebSocket API that sent r.
+// It returns false if r was not converted by a WebsocketLambda.
+func NewClientFromRequest(p client.ConfigProvider, r *http.Request) (*Client, bool) {
+	rc, ok := GetWebsocketContext(r)
+	if !ok {
+		return nil, false
+
+	}
+	return NewClient(p, Endpoint(rc)), true
+
+}
+
+// PostToConnection sends data to the client of connectionID. It returns
+// ErrConnectionGone if the client disconnected.
+func (c *Client) PostToConnection(ctx context.Context, connectionID string, data []byte) error {
+	_, err := c.api.PostToConnectionWithContext(ctx, &apigatewaymanagementapi.PostToConnectionInput{
+		ConnectionId: aws.String(connectionID),
+		Data:         data,
+	})
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == apigatewaymanagementapi.ErrCodeGoneException {
+		return ErrConnectionGone
+
+	}
+	return err
+
+}
+
+// PostToConnection sends data to the client whose message r is, using the
+// default AWS configuration. Handlers sending many messages should create a
+// Client once instead.
+func PostToConnection(ctx context.Context, r *http.Request, data []byte) error {
+	rc, ok := GetWebsocketContext(r)
+	if !ok {
+		return errors.New("websocketadapter: request was not sent by a WebSocket API")
+
+	}
+	sess, err := session.NewSession()
+	if err != nil {
+		return err
+
+	}
+	return NewClient(sess, Endpoint(rc)).PostToConnection(ctx, rc.ConnectionID, data)
+
+}
+
+// Endpoint returns the Management API endpoint of the WebSocket API a request
+// context comes from. Custom domain names cannot serve the Management API, so
+// the execute-api domain of the API, in the region of the function, is used
+// for them.
+func Endpoint(rc events.APIGatewayWebsocketProxyRequestContext) string {
+	domain := rc.DomainName
+	if !strings.Contains(domain, ".execute-api.") {
+		domain = rc.APIID + ".execute-api." + os.Getenv("AWS_REGION") + ".amazonaws.com"
+
+	}
+	return "https://" + domain + "/" + rc.Stage
+
+}